@@ -17,6 +17,9 @@ package options
 import (
 	"flag"
 	"os"
+	"strings"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 
 	"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
 )
@@ -33,6 +36,14 @@ type ServerOption struct {
 	LockNamespace      string
 	QPS                int
 	Burst              int
+	HostNetworkPorts   utilnet.PortRange
+	WaitForWorkerDNS   bool
+	EventQPS           float64
+	EventBurst         int
+	EventMaxAggregated int
+	OwnedObjectsOnly   bool
+	TopologyKeys       []string
+	SlotsResource      string
 }
 
 // NewServerOption creates a new CMServer with a default config.
@@ -68,4 +79,44 @@ func (s *ServerOption) AddFlags(fs *flag.FlagSet) {
 
 	fs.IntVar(&s.QPS, "kube-api-qps", 5, "QPS indicates the maximum QPS to the master from this client.")
 	fs.IntVar(&s.Burst, "kube-api-burst", 10, "Maximum burst for throttle.")
+
+	fs.Var(&s.HostNetworkPorts, "host-network-port-range",
+		`Range of host ports (e.g. "20000-20999") to allocate SSH ports from for jobs whose workers
+		use the host network. If unset, workers use the port configured in their image.`)
+
+	fs.BoolVar(&s.WaitForWorkerDNS, "wait-for-worker-dns", false,
+		`Delay the creation of the launcher until the controller can resolve the hostnames of all the workers.
+		Requires the controller to use the cluster DNS.`)
+
+	fs.Float64Var(&s.EventQPS, "event-qps", 0,
+		`Rate at which the events of a single object refill the event budget. If 0, one event every 5 minutes.`)
+	fs.IntVar(&s.EventBurst, "event-burst", 0,
+		`Maximum number of events recorded for a single object before rate limiting applies. If 0, 25.`)
+	fs.IntVar(&s.EventMaxAggregated, "event-max-aggregated", 0,
+		`Number of similar events for an object within 10 minutes before they are aggregated into one. If 0, 10.`)
+
+	fs.BoolVar(&s.OwnedObjectsOnly, "owned-objects-only", false,
+		`Only watch the Pods, Jobs, Services, ConfigMaps and Secrets that have the operator name label,
+		instead of all of them. Objects created by versions of the operator that didn't set the label
+		are not seen, so only enable it once no MPIJob created by such versions is running.`)
+
+	fs.Func("hostfile-topology-keys",
+		`Comma-separated node labels, from the outermost level (e.g. "topology.kubernetes.io/zone,rack"),
+		used to list the workers in the same location next to each other in the hostfile and
+		discover_hosts.sh. Workers on the same node are always listed together when set.
+		If unset, workers are listed by name.`,
+		func(v string) error {
+			s.TopologyKeys = nil
+			for _, k := range strings.Split(v, ",") {
+				if k = strings.TrimSpace(k); k != "" {
+					s.TopologyKeys = append(s.TopologyKeys, k)
+				}
+			}
+			return nil
+		})
+
+	fs.StringVar(&s.SlotsResource, "slots-per-worker-resource", "",
+		`Resource (e.g. "nvidia.com/gpu") whose amount, requested by the containers of a worker, is used as
+		the slots per worker of the MPIJobs that don't set slotsPerWorker. If unset, or if the workers
+		don't request the resource, the slots default to 1.`)
 }
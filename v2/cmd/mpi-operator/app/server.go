@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apiserver/pkg/server/healthz"
 	kubeinformers "k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	clientgokubescheme "k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -47,6 +48,7 @@ import (
 	"github.com/kubeflow/mpi-operator/v2/cmd/mpi-operator/app/options"
 	mpijobclientset "github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned"
 	informers "github.com/kubeflow/mpi-operator/v2/pkg/client/informers/externalversions"
+	kubeflowinformers "github.com/kubeflow/mpi-operator/v2/pkg/client/informers/externalversions/kubeflow/v2beta1"
 	controllersv1 "github.com/kubeflow/mpi-operator/v2/pkg/controller"
 	version "github.com/kubeflow/mpi-operator/v2/pkg/version"
 )
@@ -77,6 +79,25 @@ var (
 	})
 )
 
+// eventCorrelatorOptions returns the rate limits of the recorded events set
+// by the event flags. Zero values select the client-go defaults.
+func eventCorrelatorOptions(opt *options.ServerOption) (record.CorrelatorOptions, error) {
+	if opt.EventQPS < 0 {
+		return record.CorrelatorOptions{}, fmt.Errorf("--event-qps must not be negative, got %v", opt.EventQPS)
+	}
+	if opt.EventBurst < 0 {
+		return record.CorrelatorOptions{}, fmt.Errorf("--event-burst must not be negative, got %d", opt.EventBurst)
+	}
+	if opt.EventMaxAggregated < 0 {
+		return record.CorrelatorOptions{}, fmt.Errorf("--event-max-aggregated must not be negative, got %d", opt.EventMaxAggregated)
+	}
+	return record.CorrelatorOptions{
+		QPS:       float32(opt.EventQPS),
+		BurstSize: opt.EventBurst,
+		MaxEvents: opt.EventMaxAggregated,
+	}, nil
+}
+
 func Run(opt *options.ServerOption) error {
 	// Check if the -version flag was passed and, if so, print the version and exit.
 	if opt.PrintVersion {
@@ -96,6 +117,11 @@ func Run(opt *options.ServerOption) error {
 	// To help debugging, immediately log opts.
 	klog.Infof("Server options: %+v", opt)
 
+	eventOptions, err := eventCorrelatorOptions(opt)
+	if err != nil {
+		return err
+	}
+
 	// set up signals so we handle the first shutdown signal gracefully
 	stopCh := signals.SetupSignalHandler()
 
@@ -123,6 +149,27 @@ func Run(opt *options.ServerOption) error {
 		klog.Info("CRD doesn't exist. Exiting")
 		os.Exit(1)
 	}
+	cronEnabled := optionalCRDExists(func() error {
+		_, err := mpiJobClientSet.KubeflowV2beta1().CronMPIJobs(namespace).List(context.TODO(), metav1.ListOptions{})
+		return err
+	})
+	if !cronEnabled {
+		klog.Info("CronMPIJob CRD isn't available. CronMPIJobs are disabled")
+	}
+	arrayEnabled := optionalCRDExists(func() error {
+		_, err := mpiJobClientSet.KubeflowV2beta1().MPIJobArrays(namespace).List(context.TODO(), metav1.ListOptions{})
+		return err
+	})
+	if !arrayEnabled {
+		klog.Info("MPIJobArray CRD isn't available. MPIJobArrays are disabled")
+	}
+	templateEnabled := optionalCRDExists(func() error {
+		_, err := mpiJobClientSet.KubeflowV2beta1().MPIJobTemplates(namespace).List(context.TODO(), metav1.ListOptions{})
+		return err
+	})
+	if !templateEnabled {
+		klog.Info("MPIJobTemplate CRD isn't available. MPIJobTemplates are disabled")
+	}
 
 	// Add mpi-job-controller types to the default Kubernetes Scheme so Events
 	// can be logged for mpi-job-controller types.
@@ -136,12 +183,17 @@ func Run(opt *options.ServerOption) error {
 		var kubeInformerFactory kubeinformers.SharedInformerFactory
 		var kubeflowInformerFactory informers.SharedInformerFactory
 		var volcanoInformerFactory volcanoinformers.SharedInformerFactory
+		var kubeInformerOptions []kubeinformers.SharedInformerOption
+		if opt.OwnedObjectsOnly {
+			kubeInformerOptions = append(kubeInformerOptions, kubeinformers.WithTweakListOptions(controllersv1.SelectOwnedObjects))
+		}
 		if namespace == metav1.NamespaceAll {
-			kubeInformerFactory = kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+			kubeInformerFactory = kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeInformerOptions...)
 			kubeflowInformerFactory = informers.NewSharedInformerFactory(mpiJobClientSet, 0)
 			volcanoInformerFactory = volcanoinformers.NewSharedInformerFactory(volcanoClientSet, 0)
 		} else {
-			kubeInformerFactory = kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace))
+			kubeInformerOptions = append(kubeInformerOptions, kubeinformers.WithNamespace(namespace))
+			kubeInformerFactory = kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeInformerOptions...)
 			kubeflowInformerFactory = informers.NewSharedInformerFactoryWithOptions(mpiJobClientSet, 0, informers.WithNamespace(namespace))
 			volcanoInformerFactory = volcanoinformers.NewSharedInformerFactoryWithOptions(volcanoClientSet, 0, volcanoinformers.WithNamespace(namespace))
 		}
@@ -150,6 +202,18 @@ func Run(opt *options.ServerOption) error {
 		if opt.GangSchedulingName != "" {
 			podgroupsInformer = volcanoInformerFactory.Scheduling().V1beta1().PodGroups()
 		}
+		var templateInformer kubeflowinformers.MPIJobTemplateInformer
+		if templateEnabled {
+			templateInformer = kubeflowInformerFactory.Kubeflow().V2beta1().MPIJobTemplates()
+		}
+		// Nodes are cluster scoped and don't have the operator label, so they
+		// are watched with their own factory.
+		var nodeInformerFactory kubeinformers.SharedInformerFactory
+		var nodeInformer coreinformers.NodeInformer
+		if len(opt.TopologyKeys) > 0 {
+			nodeInformerFactory = kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+			nodeInformer = nodeInformerFactory.Core().V1().Nodes()
+		}
 		controller := controllersv1.NewMPIJobController(
 			kubeClient,
 			mpiJobClientSet,
@@ -161,16 +225,59 @@ func Run(opt *options.ServerOption) error {
 			kubeInformerFactory.Core().V1().Pods(),
 			podgroupsInformer,
 			kubeflowInformerFactory.Kubeflow().V2beta1().MPIJobs(),
-			opt.GangSchedulingName)
+			templateInformer,
+			nodeInformer,
+			opt.GangSchedulingName,
+			opt.HostNetworkPorts,
+			opt.WaitForWorkerDNS,
+			opt.TopologyKeys,
+			corev1.ResourceName(opt.SlotsResource),
+			eventOptions)
+
+		var cronController *controllersv1.CronMPIJobController
+		if cronEnabled {
+			cronController = controllersv1.NewCronMPIJobController(
+				kubeClient,
+				mpiJobClientSet,
+				kubeflowInformerFactory.Kubeflow().V2beta1().CronMPIJobs(),
+				kubeflowInformerFactory.Kubeflow().V2beta1().MPIJobs(),
+				eventOptions)
+		}
+		var arrayController *controllersv1.MPIJobArrayController
+		if arrayEnabled {
+			arrayController = controllersv1.NewMPIJobArrayController(
+				kubeClient,
+				mpiJobClientSet,
+				kubeflowInformerFactory.Kubeflow().V2beta1().MPIJobArrays(),
+				kubeflowInformerFactory.Kubeflow().V2beta1().MPIJobs(),
+				eventOptions)
+		}
 
 		go kubeInformerFactory.Start(ctx.Done())
 		go kubeflowInformerFactory.Start(ctx.Done())
 		if opt.GangSchedulingName != "" {
 			go volcanoInformerFactory.Start(ctx.Done())
 		}
+		if nodeInformerFactory != nil {
+			go nodeInformerFactory.Start(ctx.Done())
+		}
 
 		// Set leader election start function.
 		isLeader.Set(1)
+		if cronController != nil {
+			go func() {
+				if err := cronController.Run(opt.Threadiness, stopCh); err != nil {
+					klog.Errorf("Error running CronMPIJob controller: %s", err.Error())
+				}
+			}()
+		}
+		if arrayController != nil {
+			go func() {
+				if err := arrayController.Run(opt.Threadiness, stopCh); err != nil {
+					klog.Errorf("Error running MPIJobArray controller: %s", err.Error())
+				}
+			}()
+		}
 		if err = controller.Run(opt.Threadiness, stopCh); err != nil {
 			klog.Fatalf("Error running controller: %s", err.Error())
 		}
@@ -184,7 +291,7 @@ func Run(opt *options.ServerOption) error {
 	id = id + "_" + string(uuid.NewUUID())
 
 	// Prepare event clients.
-	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster := record.NewBroadcasterWithCorrelatorOptions(eventOptions)
 	eventBroadcaster.StartLogging(klog.Infof)
 	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(clientgokubescheme.Scheme, corev1.EventSource{Component: controllerName})
@@ -297,3 +404,16 @@ func checkCRDExists(clientset mpijobclientset.Interface, namespace string) bool
 	}
 	return true
 }
+
+// optionalCRDExists returns whether the list call of an optional kind
+// succeeds. Errors other than NotFound are logged.
+func optionalCRDExists(list func() error) bool {
+	err := list()
+	if err == nil {
+		return true
+	}
+	if !errors.IsNotFound(err) {
+		klog.Error(err)
+	}
+	return false
+}
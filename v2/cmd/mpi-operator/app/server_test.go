@@ -0,0 +1,102 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kubeflow/mpi-operator/v2/cmd/mpi-operator/app/options"
+)
+
+func TestEventCorrelatorOptions(t *testing.T) {
+	cases := map[string]struct {
+		args    []string
+		want    record.CorrelatorOptions
+		wantErr bool
+	}{
+		"defaults": {},
+		"all set": {
+			args: []string{"--event-qps=0.5", "--event-burst=50", "--event-max-aggregated=20"},
+			want: record.CorrelatorOptions{
+				QPS:       0.5,
+				BurstSize: 50,
+				MaxEvents: 20,
+			},
+		},
+		"negative qps": {
+			args:    []string{"--event-qps=-1"},
+			wantErr: true,
+		},
+		"negative burst": {
+			args:    []string{"--event-burst=-1"},
+			wantErr: true,
+		},
+		"negative max aggregated": {
+			args:    []string{"--event-max-aggregated=-1"},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opt := options.NewServerOption()
+			fs := flag.NewFlagSet("mpi-operator", flag.ContinueOnError)
+			opt.AddFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("Parsing flags: %v", err)
+			}
+			got, err := eventCorrelatorOptions(opt)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("eventCorrelatorOptions returned error %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected options (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOptionalCRDExists(t *testing.T) {
+	resource := schema.GroupResource{Group: "kubeflow.org", Resource: "cronmpijobs"}
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"listed": {
+			want: true,
+		},
+		"not found": {
+			err: errors.NewNotFound(resource, ""),
+		},
+		"forbidden": {
+			err: errors.NewForbidden(resource, "", nil),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := optionalCRDExists(func() error {
+				return tc.err
+			})
+			if got != tc.want {
+				t.Errorf("optionalCRDExists returned %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -59,6 +59,9 @@ func setDefaultsRunPolicy(policy *common.RunPolicy) {
 
 func SetDefaults_MPIJob(mpiJob *MPIJob) {
 	setDefaultsRunPolicy(&mpiJob.Spec.RunPolicy)
+	if p := mpiJob.Spec.RequeueOnFailure; p != nil && p.BackoffSeconds == nil {
+		p.BackoffSeconds = newInt32(60)
+	}
 	if mpiJob.Spec.SlotsPerWorker == nil {
 		mpiJob.Spec.SlotsPerWorker = newInt32(1)
 	}
@@ -68,6 +71,9 @@ func SetDefaults_MPIJob(mpiJob *MPIJob) {
 	if mpiJob.Spec.MPIImplementation == "" {
 		mpiJob.Spec.MPIImplementation = MPIImplementationOpenMPI
 	}
+	if p := mpiJob.Spec.Profiling; p != nil && p.OutputPath == "" {
+		p.OutputPath = "/profile"
+	}
 
 	// set default to Launcher
 	setDefaultsTypeLauncher(mpiJob.Spec.MPIReplicaSpecs[MPIReplicaTypeLauncher])
@@ -76,10 +82,29 @@ func SetDefaults_MPIJob(mpiJob *MPIJob) {
 	setDefaultsTypeWorker(mpiJob.Spec.MPIReplicaSpecs[MPIReplicaTypeWorker])
 }
 
+func SetDefaults_CronMPIJob(cronJob *CronMPIJob) {
+	if cronJob.Spec.ConcurrencyPolicy == "" {
+		cronJob.Spec.ConcurrencyPolicy = AllowConcurrent
+	}
+	if cronJob.Spec.Suspend == nil {
+		cronJob.Spec.Suspend = newBool(false)
+	}
+	if cronJob.Spec.SuccessfulJobsHistoryLimit == nil {
+		cronJob.Spec.SuccessfulJobsHistoryLimit = newInt32(3)
+	}
+	if cronJob.Spec.FailedJobsHistoryLimit == nil {
+		cronJob.Spec.FailedJobsHistoryLimit = newInt32(1)
+	}
+}
+
 func newInt32(v int32) *int32 {
 	return &v
 }
 
+func newBool(v bool) *bool {
+	return &v
+}
+
 func newCleanPodPolicy(policy common.CleanPodPolicy) *common.CleanPodPolicy {
 	return &policy
 }
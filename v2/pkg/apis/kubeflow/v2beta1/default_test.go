@@ -66,6 +66,48 @@ func TestSetDefaults_MPIJob(t *testing.T) {
 				},
 			},
 		},
+		"requeue defaults": {
+			job: MPIJob{
+				Spec: MPIJobSpec{
+					RequeueOnFailure: &RequeuePolicy{MaxRequeues: 3},
+				},
+			},
+			want: MPIJob{
+				Spec: MPIJobSpec{
+					SlotsPerWorker: newInt32(1),
+					RunPolicy: common.RunPolicy{
+						CleanPodPolicy: newCleanPodPolicy(common.CleanPodPolicyNone),
+					},
+					RequeueOnFailure: &RequeuePolicy{
+						MaxRequeues:    3,
+						BackoffSeconds: newInt32(60),
+					},
+					SSHAuthMountPath:  "/root/.ssh",
+					MPIImplementation: MPIImplementationOpenMPI,
+				},
+			},
+		},
+		"profiling defaults": {
+			job: MPIJob{
+				Spec: MPIJobSpec{
+					Profiling: &Profiling{OutputClaimName: "results"},
+				},
+			},
+			want: MPIJob{
+				Spec: MPIJobSpec{
+					SlotsPerWorker: newInt32(1),
+					RunPolicy: common.RunPolicy{
+						CleanPodPolicy: newCleanPodPolicy(common.CleanPodPolicyNone),
+					},
+					SSHAuthMountPath:  "/root/.ssh",
+					MPIImplementation: MPIImplementationOpenMPI,
+					Profiling: &Profiling{
+						OutputClaimName: "results",
+						OutputPath:      "/profile",
+					},
+				},
+			},
+		},
 		"launcher defaults": {
 			job: MPIJob{
 				Spec: MPIJobSpec{
@@ -128,6 +170,51 @@ func TestSetDefaults_MPIJob(t *testing.T) {
 	}
 }
 
+func TestSetDefaults_CronMPIJob(t *testing.T) {
+	cases := map[string]struct {
+		job  CronMPIJob
+		want CronMPIJob
+	}{
+		"base defaults": {
+			want: CronMPIJob{
+				Spec: CronMPIJobSpec{
+					ConcurrencyPolicy:          AllowConcurrent,
+					Suspend:                    newBool(false),
+					SuccessfulJobsHistoryLimit: newInt32(3),
+					FailedJobsHistoryLimit:     newInt32(1),
+				},
+			},
+		},
+		"base defaults overridden": {
+			job: CronMPIJob{
+				Spec: CronMPIJobSpec{
+					ConcurrencyPolicy:          ForbidConcurrent,
+					Suspend:                    newBool(true),
+					SuccessfulJobsHistoryLimit: newInt32(0),
+					FailedJobsHistoryLimit:     newInt32(5),
+				},
+			},
+			want: CronMPIJob{
+				Spec: CronMPIJobSpec{
+					ConcurrencyPolicy:          ForbidConcurrent,
+					Suspend:                    newBool(true),
+					SuccessfulJobsHistoryLimit: newInt32(0),
+					FailedJobsHistoryLimit:     newInt32(5),
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.job.DeepCopy()
+			SetDefaults_CronMPIJob(got)
+			if diff := cmp.Diff(tc.want, *got); diff != "" {
+				t.Errorf("Unexpected changes (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func newInt64(v int64) *int64 {
 	return &v
 }
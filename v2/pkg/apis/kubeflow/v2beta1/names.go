@@ -0,0 +1,47 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2beta1
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+const (
+	// maxUnshortenedNameLength is the longest MPIJob name used as is in the
+	// names of its resources. It leaves room for the hostname of the first
+	// worker, "<name>-worker-0", within a DNS label. Longer names could never
+	// be used, so shortening them doesn't rename the resources of any job.
+	maxUnshortenedNameLength = 54
+	// shortenedNameLength is the length of a shortened name. It leaves room
+	// for the hostnames of up to 100000 workers.
+	shortenedNameLength = 50
+)
+
+// ChildNamePrefix returns the prefix of the names of the resources created
+// for the MPIJob with the given name. Names too long to prefix the worker
+// hostnames are truncated and suffixed with a hash of the whole name, so
+// that MPIJobs sharing a long prefix don't clash.
+func ChildNamePrefix(name string) string {
+	if len(name) <= maxUnshortenedNameLength {
+		return name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	hash := fmt.Sprintf("%08x", h.Sum32())
+	head := strings.TrimRight(name[:shortenedNameLength-len(hash)-1], "-.")
+	return head + "-" + hash
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2beta1
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChildNamePrefix(t *testing.T) {
+	cases := map[string]struct {
+		name string
+		want string
+	}{
+		"short": {
+			name: "foo",
+			want: "foo",
+		},
+		"longest unshortened": {
+			name: strings.Repeat("a", 54),
+			want: strings.Repeat("a", 54),
+		},
+		"shortened": {
+			name: strings.Repeat("a", 55),
+			want: strings.Repeat("a", 41) + "-2929d72e",
+		},
+		"same prefix": {
+			name: strings.Repeat("a", 54) + "b",
+			want: strings.Repeat("a", 41) + "-2829d59b",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ChildNamePrefix(tc.name)
+			if got != tc.want {
+				t.Errorf("ChildNamePrefix(%q) = %q, want %q", tc.name, got, tc.want)
+			}
+		})
+	}
+}
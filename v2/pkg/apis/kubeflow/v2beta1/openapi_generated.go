@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 // Copyright 2021 The Kubeflow Authors
@@ -27,15 +28,31 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/kubeflow/common/pkg/apis/common/v1.JobCondition":               schema_pkg_apis_common_v1_JobCondition(ref),
-		"github.com/kubeflow/common/pkg/apis/common/v1.JobStatus":                  schema_pkg_apis_common_v1_JobStatus(ref),
-		"github.com/kubeflow/common/pkg/apis/common/v1.ReplicaSpec":                schema_pkg_apis_common_v1_ReplicaSpec(ref),
-		"github.com/kubeflow/common/pkg/apis/common/v1.ReplicaStatus":              schema_pkg_apis_common_v1_ReplicaStatus(ref),
-		"github.com/kubeflow/common/pkg/apis/common/v1.RunPolicy":                  schema_pkg_apis_common_v1_RunPolicy(ref),
-		"github.com/kubeflow/common/pkg/apis/common/v1.SchedulingPolicy":           schema_pkg_apis_common_v1_SchedulingPolicy(ref),
-		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJob":     schema_pkg_apis_kubeflow_v2beta1_MPIJob(ref),
-		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobList": schema_pkg_apis_kubeflow_v2beta1_MPIJobList(ref),
-		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec": schema_pkg_apis_kubeflow_v2beta1_MPIJobSpec(ref),
+		"github.com/kubeflow/common/pkg/apis/common/v1.JobCondition":                       schema_pkg_apis_common_v1_JobCondition(ref),
+		"github.com/kubeflow/common/pkg/apis/common/v1.JobStatus":                          schema_pkg_apis_common_v1_JobStatus(ref),
+		"github.com/kubeflow/common/pkg/apis/common/v1.ReplicaSpec":                        schema_pkg_apis_common_v1_ReplicaSpec(ref),
+		"github.com/kubeflow/common/pkg/apis/common/v1.ReplicaStatus":                      schema_pkg_apis_common_v1_ReplicaStatus(ref),
+		"github.com/kubeflow/common/pkg/apis/common/v1.RunPolicy":                          schema_pkg_apis_common_v1_RunPolicy(ref),
+		"github.com/kubeflow/common/pkg/apis/common/v1.SchedulingPolicy":                   schema_pkg_apis_common_v1_SchedulingPolicy(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJob":         schema_pkg_apis_kubeflow_v2beta1_CronMPIJob(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJobList":     schema_pkg_apis_kubeflow_v2beta1_CronMPIJobList(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJobSpec":     schema_pkg_apis_kubeflow_v2beta1_CronMPIJobSpec(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJobStatus":   schema_pkg_apis_kubeflow_v2beta1_CronMPIJobStatus(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJob":             schema_pkg_apis_kubeflow_v2beta1_MPIJob(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArray":        schema_pkg_apis_kubeflow_v2beta1_MPIJobArray(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArrayList":    schema_pkg_apis_kubeflow_v2beta1_MPIJobArrayList(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArraySpec":    schema_pkg_apis_kubeflow_v2beta1_MPIJobArraySpec(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArrayStatus":  schema_pkg_apis_kubeflow_v2beta1_MPIJobArrayStatus(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobList":         schema_pkg_apis_kubeflow_v2beta1_MPIJobList(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec":         schema_pkg_apis_kubeflow_v2beta1_MPIJobSpec(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplate":     schema_pkg_apis_kubeflow_v2beta1_MPIJobTemplate(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplateList": schema_pkg_apis_kubeflow_v2beta1_MPIJobTemplateList(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplateSpec": schema_pkg_apis_kubeflow_v2beta1_MPIJobTemplateSpec(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MetadataPolicy":     schema_pkg_apis_kubeflow_v2beta1_MetadataPolicy(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.ParameterSet":       schema_pkg_apis_kubeflow_v2beta1_ParameterSet(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.Profiling":          schema_pkg_apis_kubeflow_v2beta1_Profiling(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.RequeuePolicy":      schema_pkg_apis_kubeflow_v2beta1_RequeuePolicy(ref),
+		"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.SSHOptions":         schema_pkg_apis_kubeflow_v2beta1_SSHOptions(ref),
 	}
 }
 
@@ -320,11 +337,12 @@ func schema_pkg_apis_common_v1_SchedulingPolicy(ref common.ReferenceCallback) co
 	}
 }
 
-func schema_pkg_apis_kubeflow_v2beta1_MPIJob(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_kubeflow_v2beta1_CronMPIJob(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "CronMPIJob creates MPIJobs on a repeating schedule.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
 						SchemaProps: spec.SchemaProps{
@@ -347,23 +365,23 @@ func schema_pkg_apis_kubeflow_v2beta1_MPIJob(ref common.ReferenceCallback) commo
 					},
 					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec"),
+							Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJobSpec"),
 						},
 					},
 					"status": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/kubeflow/common/pkg/apis/common/v1.JobStatus"),
+							Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJobStatus"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/kubeflow/common/pkg/apis/common/v1.JobStatus", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJobSpec", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJobStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_pkg_apis_kubeflow_v2beta1_MPIJobList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_kubeflow_v2beta1_CronMPIJobList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
@@ -394,7 +412,7 @@ func schema_pkg_apis_kubeflow_v2beta1_MPIJobList(ref common.ReferenceCallback) c
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJob"),
+										Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJob"),
 									},
 								},
 							},
@@ -405,62 +423,778 @@ func schema_pkg_apis_kubeflow_v2beta1_MPIJobList(ref common.ReferenceCallback) c
 			},
 		},
 		Dependencies: []string{
-			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJob", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.CronMPIJob", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
 	}
 }
 
-func schema_pkg_apis_kubeflow_v2beta1_MPIJobSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_kubeflow_v2beta1_CronMPIJobSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
 				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"slotsPerWorker": {
+					"schedule": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Specifies the number of slots per worker used in hostfile. Defaults to 1.",
+							Description: "Schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startingDeadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartingDeadlineSeconds is the deadline in seconds for starting the MPIJob if it misses its scheduled time for any reason. Missed runs are counted as failed ones.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"concurrencyPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConcurrencyPolicy specifies how to treat concurrent runs. Options are \"Allow\" (default), \"Forbid\" and \"Replace\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Suspend tells the controller to suspend subsequent runs. It doesn't apply to already started runs.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"jobTemplate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JobTemplate is the template of the MPIJobs created on each run.",
+							Ref:         ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplateSpec"),
+						},
+					},
+					"successfulJobsHistoryLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SuccessfulJobsHistoryLimit is the number of successful finished MPIJobs to keep.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"runPolicy": {
+					"failedJobsHistoryLimit": {
 						SchemaProps: spec.SchemaProps{
-							Description: "RunPolicy encapsulates various runtime policies of the job.",
-							Ref:         ref("github.com/kubeflow/common/pkg/apis/common/v1.RunPolicy"),
+							Description: "FailedJobsHistoryLimit is the number of failed finished MPIJobs to keep.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"mpiReplicaSpecs": {
+				},
+				Required: []string{"schedule", "jobTemplate"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplateSpec"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_CronMPIJobStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"active": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MPIReplicaSpecs contains maps from `MPIReplicaType` to `ReplicaSpec` that specify the MPI replicas to run.",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "Active is the list of references to the running MPIJobs.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/kubeflow/common/pkg/apis/common/v1.ReplicaSpec"),
+										Ref: ref("k8s.io/api/core/v1.ObjectReference"),
 									},
 								},
 							},
 						},
 					},
-					"sshAuthMountPath": {
+					"lastScheduleTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SSHAuthMountPath is the directory where SSH keys are mounted. Defaults to \"/root/.ssh\".",
+							Description: "LastScheduleTime is the last time the MPIJob was successfully scheduled.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ObjectReference", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJob(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"mpiImplementation": {
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/kubeflow/common/pkg/apis/common/v1.JobStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/common/pkg/apis/common/v1.JobStatus", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobArray(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MPIJobArray creates one MPIJob per parameter set, for parameter studies where every instance needs its own MPI allocation.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArraySpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArrayStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArraySpec", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArrayStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobArrayList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "MPIImplementation is the MPI implementation. Options are \"OpenMPI\" (default) and \"Intel\".",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArray"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobArray", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobArraySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"jobTemplate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JobTemplate is the template of the MPIJobs of the array. All the instances share its run policy, including the priority class.",
+							Ref:         ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplateSpec"),
+						},
+					},
+					"parameters": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Parameters is the list of parameter sets. The controller creates one MPIJob per set, named <array name>-<index>.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.ParameterSet"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"jobTemplate", "parameters"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplateSpec", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.ParameterSet"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobArrayStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"active": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Active is the number of MPIJobs that haven't finished.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"succeeded": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Succeeded is the number of MPIJobs that succeeded.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Failed is the number of MPIJobs that failed, including the ones that were invalid and couldn't be created.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"completionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CompletionTime is the time when all the MPIJobs finished.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 				},
-				Required: []string{"runPolicy", "mpiReplicaSpecs"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/kubeflow/common/pkg/apis/common/v1.ReplicaSpec", "github.com/kubeflow/common/pkg/apis/common/v1.RunPolicy"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJob"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJob", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"slotsPerWorker": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specifies the number of slots per worker used in hostfile. Defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"runPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RunPolicy encapsulates various runtime policies of the job.",
+							Ref:         ref("github.com/kubeflow/common/pkg/apis/common/v1.RunPolicy"),
+						},
+					},
+					"requeueOnFailure": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RequeueOnFailure retries the MPIJob from scratch, with new workers and launcher, when the launcher Job fails after reaching its backoff limit. By default, the MPIJob fails.",
+							Ref:         ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.RequeuePolicy"),
+						},
+					},
+					"deadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeadlineSeconds is the duration in seconds, relative to the creation of the MPIJob, that the MPIJob may take to complete. Unlike runPolicy.activeDeadlineSeconds, it includes the time waiting for dependencies and for the workers to be provisioned. The MPIJob fails once the deadline is exceeded.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"mpiReplicaSpecs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MPIReplicaSpecs contains maps from `MPIReplicaType` to `ReplicaSpec` that specify the MPI replicas to run. Required unless templateName is set, in which case they override the replica specs of the template.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/kubeflow/common/pkg/apis/common/v1.ReplicaSpec"),
+									},
+								},
+							},
+						},
+					},
+					"dependsOn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DependsOn is the list of names of MPIJobs in the same namespace that must succeed before this MPIJob starts. The MPIJob fails if any of them fails or if they depend on this MPIJob. The MPIJob waits for the ones that don't exist until they are created, with no limit other than deadlineSeconds.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"templateName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TemplateName is the name of an MPIJobTemplate in the same namespace that this MPIJob is based on. The fields set in this MPIJob override the ones in the template.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sshAuthMountPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SSHAuthMountPath is the directory where SSH keys are mounted. Defaults to \"/root/.ssh\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sshOptions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SSHOptions configures the SSH client that the launcher uses to start the processes in the workers.",
+							Ref:         ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.SSHOptions"),
+						},
+					},
+					"mpiImplementation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MPIImplementation is the MPI implementation. Options are \"OpenMPI\" (default), \"Intel\" and \"Charm\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"hostDiscoveryNetwork": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HostDiscoveryNetwork is the name of a secondary network, as reported in the Multus network-status annotation of the workers, whose addresses are used in the hostfile and discover_hosts.sh instead of the worker hostnames. The launcher is created once all the workers report an address in this network. Workers that don't report one yet, such as the ones added to a running job, are listed by hostname.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadataPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MetadataPolicy holds labels and annotations that the controller adds to the launcher Job, workers, Services, ConfigMap, Secret and PodGroup that it creates for the MPIJob.",
+							Ref:         ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MetadataPolicy"),
+						},
+					},
+					"profiling": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Profiling runs a profiler in the MPI processes of the launcher and the workers.",
+							Ref:         ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.Profiling"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/common/pkg/apis/common/v1.ReplicaSpec", "github.com/kubeflow/common/pkg/apis/common/v1.RunPolicy", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MetadataPolicy", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.Profiling", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.RequeuePolicy", "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.SSHOptions"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobTemplate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MPIJobTemplate holds a reusable MPIJob spec, such as the images, SSH setup and volumes blessed by a platform team. MPIJobs reference it in spec.templateName and override a subset of its fields:\n  - The scalar fields, SSH options and run policy fields that the MPIJob\n    sets replace the ones in the template.\n  - Replica specs are merged by replica type. Replicas and restart policy\n    replace the ones in the template.\n  - Pod templates are merged like a strategic merge patch. The fields that\n    the MPIJob sets replace the ones in the template. Maps, such as labels,\n    node selectors and resources, are merged by key. Containers, volumes\n    and environment variables are merged by name. Other lists, such as\n    command, args and tolerations, are replaced.\n\nMPIJobs record the spec of the template until they start. Changes to an MPIJobTemplate, or its deletion, don't affect the MPIJobs that already started.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobTemplateList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplate"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobTemplate", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MPIJobTemplateSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MPIJobTemplateSpec describes the MPIJob to create from a template.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Standard object's metadata of the MPIJobs created from this template.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec of the MPIJobs created from this template.",
+							Ref:         ref("github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1.MPIJobSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_MetadataPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MetadataPolicy holds metadata for the objects created for an MPIJob. It doesn't override the labels and annotations set by the controller or in the pod templates.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels to add to the objects.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"annotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotations to add to the objects.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_ParameterSet(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ParameterSet holds the values of one instance of an MPIJobArray.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Env is the list of environment variables to set in all the containers of the launcher and workers of the instance, overriding the ones with the same name in the template. They can be referenced in commands and arguments with the $(VAR_NAME) syntax.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.EnvVar"},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_Profiling(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Profiling configures a profiler, such as mpiP or Score-P, that is loaded in the MPI processes through environment variables.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Env holds the environment variables that enable the profiler, such as LD_PRELOAD. They are set in the first container of the launcher and the workers. With OpenMPI, they are also forwarded to the processes started by mpirun.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"outputClaimName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OutputClaimName is the name of a PersistentVolumeClaim, in the namespace of the MPIJob, where the profiler writes its output. It is mounted at OutputPath in the launcher and the workers, so it needs the ReadWriteMany access mode when they run in different nodes.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"outputPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OutputPath is the directory where the output claim is mounted. It is available to the processes in the K_MPI_PROFILE_DIR environment variable. Defaults to \"/profile\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_RequeuePolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RequeuePolicy configures the retries of an MPIJob whose launcher failed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"maxRequeues": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxRequeues is the number of times the MPIJob is retried before it is marked as failed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"backoffSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackoffSeconds is the time to wait after the launcher failed before retrying. Defaults to 60.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"maxRequeues"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_kubeflow_v2beta1_SSHOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SSHOptions are the options passed to the SSH client of the launcher.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"connectionAttempts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConnectionAttempts is the number of tries, one per second, to connect to a worker before giving up. Defaults to 10.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"serverAliveInterval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ServerAliveInterval is the number of seconds of inactivity after which the client probes the worker's sshd. Unset or 0 disables the probes.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"serverAliveCountMax": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ServerAliveCountMax is the number of unanswered probes after which the client disconnects from the worker.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"ciphers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ciphers is the list of allowed ciphers, in order of preference.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"proxyJump": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProxyJump is a comma-separated list of jump hosts, in the form [user@]host[:port], through which the launcher connects to the workers. The jump hosts must accept the job's SSH key.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
 	}
 }
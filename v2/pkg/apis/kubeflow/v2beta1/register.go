@@ -46,6 +46,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&MPIJob{},
 		&MPIJobList{},
+		&CronMPIJob{},
+		&CronMPIJobList{},
+		&MPIJobArray{},
+		&MPIJobArrayList{},
+		&MPIJobTemplate{},
+		&MPIJobTemplateList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
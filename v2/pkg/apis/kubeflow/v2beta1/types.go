@@ -16,11 +16,13 @@ package v2beta1
 
 import (
 	common "github.com/kubeflow/common/pkg/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:scale:specpath=.spec.mpiReplicaSpecs.Worker.replicas,statuspath=.status.replicaStatuses.Worker.active
 
 type MPIJob struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -40,26 +42,157 @@ type MPIJobList struct {
 type MPIJobSpec struct {
 
 	// Specifies the number of slots per worker used in hostfile.
+	// Defaults to 1.
 	// +optional
-	// +kubebuilder:default:=1
 	SlotsPerWorker *int32 `json:"slotsPerWorker,omitempty"`
 
 	// RunPolicy encapsulates various runtime policies of the job.
 	RunPolicy common.RunPolicy `json:"runPolicy,omitempty"`
 
+	// RequeueOnFailure retries the MPIJob from scratch, with new workers and
+	// launcher, when the launcher Job fails after reaching its backoff limit.
+	// By default, the MPIJob fails.
+	// +optional
+	RequeueOnFailure *RequeuePolicy `json:"requeueOnFailure,omitempty"`
+
+	// DeadlineSeconds is the duration in seconds, relative to the creation
+	// of the MPIJob, that the MPIJob may take to complete. Unlike
+	// runPolicy.activeDeadlineSeconds, it includes the time waiting for
+	// dependencies and for the workers to be provisioned. The MPIJob fails
+	// once the deadline is exceeded.
+	// +optional
+	DeadlineSeconds *int64 `json:"deadlineSeconds,omitempty"`
+
 	// MPIReplicaSpecs contains maps from `MPIReplicaType` to `ReplicaSpec` that
-	// specify the MPI replicas to run.
-	MPIReplicaSpecs map[MPIReplicaType]*common.ReplicaSpec `json:"mpiReplicaSpecs"`
+	// specify the MPI replicas to run. Required unless templateName is set, in
+	// which case they override the replica specs of the template.
+	// +optional
+	MPIReplicaSpecs map[MPIReplicaType]*common.ReplicaSpec `json:"mpiReplicaSpecs,omitempty"`
+
+	// DependsOn is the list of names of MPIJobs in the same namespace that
+	// must succeed before this MPIJob starts. The MPIJob fails if any of them
+	// fails or if they depend on this MPIJob. The MPIJob waits for the ones
+	// that don't exist until they are created, with no limit other than
+	// deadlineSeconds.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// TemplateName is the name of an MPIJobTemplate in the same namespace
+	// that this MPIJob is based on. The fields set in this MPIJob override
+	// the ones in the template.
+	// +optional
+	TemplateName string `json:"templateName,omitempty"`
 
 	// SSHAuthMountPath is the directory where SSH keys are mounted.
-	// +kubebuilder:default:="/root/.ssh"
+	// Defaults to "/root/.ssh".
 	SSHAuthMountPath string `json:"sshAuthMountPath,omitempty"`
 
+	// SSHOptions configures the SSH client that the launcher uses to start
+	// the processes in the workers.
+	// +optional
+	SSHOptions *SSHOptions `json:"sshOptions,omitempty"`
+
 	// MPIImplementation is the MPI implementation.
-	// Options are "OpenMPI" (default) and "Intel".
-	// +kubebuilder:validation:Enum:=OpenMPI;Intel
-	// +kubebuilder:default:=OpenMPI
+	// Options are "OpenMPI" (default), "Intel" and "Charm".
+	// +kubebuilder:validation:Enum:=OpenMPI;Intel;Charm
 	MPIImplementation MPIImplementation `json:"mpiImplementation,omitempty"`
+
+	// HostDiscoveryNetwork is the name of a secondary network, as reported in
+	// the Multus network-status annotation of the workers, whose addresses
+	// are used in the hostfile and discover_hosts.sh instead of the worker
+	// hostnames. The launcher is created once all the workers report an
+	// address in this network. Workers that don't report one yet, such as
+	// the ones added to a running job, are listed by hostname.
+	// +optional
+	HostDiscoveryNetwork string `json:"hostDiscoveryNetwork,omitempty"`
+
+	// MetadataPolicy holds labels and annotations that the controller adds
+	// to the launcher Job, workers, Services, ConfigMap, Secret and PodGroup
+	// that it creates for the MPIJob.
+	// +optional
+	MetadataPolicy *MetadataPolicy `json:"metadataPolicy,omitempty"`
+
+	// Profiling runs a profiler in the MPI processes of the launcher and the
+	// workers.
+	// +optional
+	Profiling *Profiling `json:"profiling,omitempty"`
+}
+
+// MetadataPolicy holds metadata for the objects created for an MPIJob. It
+// doesn't override the labels and annotations set by the controller or in
+// the pod templates.
+type MetadataPolicy struct {
+	// Labels to add to the objects.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to add to the objects.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Profiling configures a profiler, such as mpiP or Score-P, that is loaded in
+// the MPI processes through environment variables.
+type Profiling struct {
+	// Env holds the environment variables that enable the profiler, such as
+	// LD_PRELOAD. They are set in the first container of the launcher and
+	// the workers. With OpenMPI, they are also forwarded to the processes
+	// started by mpirun.
+	// +optional
+	Env map[string]string `json:"env,omitempty"`
+
+	// OutputClaimName is the name of a PersistentVolumeClaim, in the
+	// namespace of the MPIJob, where the profiler writes its output. It is
+	// mounted at OutputPath in the launcher and the workers, so it needs the
+	// ReadWriteMany access mode when they run in different nodes.
+	// +optional
+	OutputClaimName string `json:"outputClaimName,omitempty"`
+
+	// OutputPath is the directory where the output claim is mounted. It is
+	// available to the processes in the K_MPI_PROFILE_DIR environment
+	// variable. Defaults to "/profile".
+	// +optional
+	OutputPath string `json:"outputPath,omitempty"`
+}
+
+// RequeuePolicy configures the retries of an MPIJob whose launcher failed.
+type RequeuePolicy struct {
+	// MaxRequeues is the number of times the MPIJob is retried before it is
+	// marked as failed.
+	MaxRequeues int32 `json:"maxRequeues"`
+
+	// BackoffSeconds is the time to wait after the launcher failed before
+	// retrying. Defaults to 60.
+	// +optional
+	BackoffSeconds *int32 `json:"backoffSeconds,omitempty"`
+}
+
+// SSHOptions are the options passed to the SSH client of the launcher.
+type SSHOptions struct {
+	// ConnectionAttempts is the number of tries, one per second, to connect
+	// to a worker before giving up. Defaults to 10.
+	// +optional
+	ConnectionAttempts *int32 `json:"connectionAttempts,omitempty"`
+
+	// ServerAliveInterval is the number of seconds of inactivity after which
+	// the client probes the worker's sshd. Unset or 0 disables the probes.
+	// +optional
+	ServerAliveInterval *int32 `json:"serverAliveInterval,omitempty"`
+
+	// ServerAliveCountMax is the number of unanswered probes after which the
+	// client disconnects from the worker.
+	// +optional
+	ServerAliveCountMax *int32 `json:"serverAliveCountMax,omitempty"`
+
+	// Ciphers is the list of allowed ciphers, in order of preference.
+	// +optional
+	Ciphers []string `json:"ciphers,omitempty"`
+
+	// ProxyJump is a comma-separated list of jump hosts, in the form
+	// [user@]host[:port], through which the launcher connects to the
+	// workers. The jump hosts must accept the job's SSH key.
+	// +optional
+	ProxyJump string `json:"proxyJump,omitempty"`
 }
 
 // MPIReplicaType is the type for MPIReplica.
@@ -78,4 +211,185 @@ type MPIImplementation string
 const (
 	MPIImplementationOpenMPI MPIImplementation = "OpenMPI"
 	MPIImplementationIntel   MPIImplementation = "Intel"
+	MPIImplementationCharm   MPIImplementation = "Charm"
 )
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+
+// CronMPIJob creates MPIJobs on a repeating schedule.
+type CronMPIJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CronMPIJobSpec   `json:"spec,omitempty"`
+	Status            CronMPIJobStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type CronMPIJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CronMPIJob `json:"items"`
+}
+
+type CronMPIJobSpec struct {
+	// Schedule in Cron format, see https://en.wikipedia.org/wiki/Cron.
+	Schedule string `json:"schedule"`
+
+	// StartingDeadlineSeconds is the deadline in seconds for starting the
+	// MPIJob if it misses its scheduled time for any reason. Missed runs are
+	// counted as failed ones.
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// ConcurrencyPolicy specifies how to treat concurrent runs.
+	// Options are "Allow" (default), "Forbid" and "Replace".
+	// +kubebuilder:validation:Enum:=Allow;Forbid;Replace
+	// +kubebuilder:default:=Allow
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend tells the controller to suspend subsequent runs. It doesn't
+	// apply to already started runs.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// JobTemplate is the template of the MPIJobs created on each run.
+	JobTemplate MPIJobTemplateSpec `json:"jobTemplate"`
+
+	// SuccessfulJobsHistoryLimit is the number of successful finished MPIJobs
+	// to keep.
+	// +kubebuilder:default:=3
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed finished MPIJobs to keep.
+	// +kubebuilder:default:=1
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// ConcurrencyPolicy describes how concurrent runs of a CronMPIJob are handled.
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows MPIJobs of a CronMPIJob to run concurrently.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent skips a run if the previous one hasn't finished yet.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent deletes the running MPIJob before starting a new run.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MPIJobTemplate holds a reusable MPIJob spec, such as the images, SSH setup
+// and volumes blessed by a platform team. MPIJobs reference it in
+// spec.templateName and override a subset of its fields:
+//   - The scalar fields, SSH options and run policy fields that the MPIJob
+//     sets replace the ones in the template.
+//   - Replica specs are merged by replica type. Replicas and restart policy
+//     replace the ones in the template.
+//   - Pod templates are merged like a strategic merge patch. The fields that
+//     the MPIJob sets replace the ones in the template. Maps, such as labels,
+//     node selectors and resources, are merged by key. Containers, volumes
+//     and environment variables are merged by name. Other lists, such as
+//     command, args and tolerations, are replaced.
+//
+// MPIJobs record the spec of the template until they start. Changes to an
+// MPIJobTemplate, or its deletion, don't affect the MPIJobs that already
+// started.
+type MPIJobTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MPIJobSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type MPIJobTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []MPIJobTemplate `json:"items"`
+}
+
+// MPIJobTemplateSpec describes the MPIJob to create from a template.
+type MPIJobTemplateSpec struct {
+	// Standard object's metadata of the MPIJobs created from this template.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec of the MPIJobs created from this template.
+	Spec MPIJobSpec `json:"spec,omitempty"`
+}
+
+type CronMPIJobStatus struct {
+	// Active is the list of references to the running MPIJobs.
+	// +optional
+	Active []corev1.ObjectReference `json:"active,omitempty"`
+
+	// LastScheduleTime is the last time the MPIJob was successfully scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+
+// MPIJobArray creates one MPIJob per parameter set, for parameter studies
+// where every instance needs its own MPI allocation.
+type MPIJobArray struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MPIJobArraySpec   `json:"spec,omitempty"`
+	Status            MPIJobArrayStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type MPIJobArrayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []MPIJobArray `json:"items"`
+}
+
+type MPIJobArraySpec struct {
+	// JobTemplate is the template of the MPIJobs of the array. All the
+	// instances share its run policy, including the priority class.
+	JobTemplate MPIJobTemplateSpec `json:"jobTemplate"`
+
+	// Parameters is the list of parameter sets. The controller creates one
+	// MPIJob per set, named <array name>-<index>.
+	Parameters []ParameterSet `json:"parameters"`
+}
+
+// ParameterSet holds the values of one instance of an MPIJobArray.
+type ParameterSet struct {
+	// Env is the list of environment variables to set in all the containers
+	// of the launcher and workers of the instance, overriding the ones with
+	// the same name in the template. They can be referenced in commands and
+	// arguments with the $(VAR_NAME) syntax.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+type MPIJobArrayStatus struct {
+	// Active is the number of MPIJobs that haven't finished.
+	// +optional
+	Active int32 `json:"active,omitempty"`
+
+	// Succeeded is the number of MPIJobs that succeeded.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of MPIJobs that failed, including the ones that
+	// were invalid and couldn't be created.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// CompletionTime is the time when all the MPIJobs finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
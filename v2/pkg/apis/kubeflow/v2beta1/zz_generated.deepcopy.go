@@ -21,9 +21,129 @@ package v2beta1
 
 import (
 	"github.com/kubeflow/common/pkg/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMPIJob) DeepCopyInto(out *CronMPIJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMPIJob.
+func (in *CronMPIJob) DeepCopy() *CronMPIJob {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMPIJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CronMPIJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMPIJobList) DeepCopyInto(out *CronMPIJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CronMPIJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMPIJobList.
+func (in *CronMPIJobList) DeepCopy() *CronMPIJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMPIJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CronMPIJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMPIJobSpec) DeepCopyInto(out *CronMPIJobSpec) {
+	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMPIJobSpec.
+func (in *CronMPIJobSpec) DeepCopy() *CronMPIJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMPIJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMPIJobStatus) DeepCopyInto(out *CronMPIJobStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]corev1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMPIJobStatus.
+func (in *CronMPIJobStatus) DeepCopy() *CronMPIJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMPIJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MPIJob) DeepCopyInto(out *MPIJob) {
 	*out = *in
@@ -51,6 +171,107 @@ func (in *MPIJob) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MPIJobArray) DeepCopyInto(out *MPIJobArray) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobArray.
+func (in *MPIJobArray) DeepCopy() *MPIJobArray {
+	if in == nil {
+		return nil
+	}
+	out := new(MPIJobArray)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MPIJobArray) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MPIJobArrayList) DeepCopyInto(out *MPIJobArrayList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MPIJobArray, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobArrayList.
+func (in *MPIJobArrayList) DeepCopy() *MPIJobArrayList {
+	if in == nil {
+		return nil
+	}
+	out := new(MPIJobArrayList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MPIJobArrayList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MPIJobArraySpec) DeepCopyInto(out *MPIJobArraySpec) {
+	*out = *in
+	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ParameterSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobArraySpec.
+func (in *MPIJobArraySpec) DeepCopy() *MPIJobArraySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MPIJobArraySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MPIJobArrayStatus) DeepCopyInto(out *MPIJobArrayStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobArrayStatus.
+func (in *MPIJobArrayStatus) DeepCopy() *MPIJobArrayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MPIJobArrayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MPIJobList) DeepCopyInto(out *MPIJobList) {
 	*out = *in
@@ -92,6 +313,16 @@ func (in *MPIJobSpec) DeepCopyInto(out *MPIJobSpec) {
 		**out = **in
 	}
 	in.RunPolicy.DeepCopyInto(&out.RunPolicy)
+	if in.RequeueOnFailure != nil {
+		in, out := &in.RequeueOnFailure, &out.RequeueOnFailure
+		*out = new(RequeuePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeadlineSeconds != nil {
+		in, out := &in.DeadlineSeconds, &out.DeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	if in.MPIReplicaSpecs != nil {
 		in, out := &in.MPIReplicaSpecs, &out.MPIReplicaSpecs
 		*out = make(map[MPIReplicaType]*v1.ReplicaSpec, len(*in))
@@ -107,6 +338,26 @@ func (in *MPIJobSpec) DeepCopyInto(out *MPIJobSpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SSHOptions != nil {
+		in, out := &in.SSHOptions, &out.SSHOptions
+		*out = new(SSHOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetadataPolicy != nil {
+		in, out := &in.MetadataPolicy, &out.MetadataPolicy
+		*out = new(MetadataPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Profiling != nil {
+		in, out := &in.Profiling, &out.Profiling
+		*out = new(Profiling)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobSpec.
@@ -118,3 +369,209 @@ func (in *MPIJobSpec) DeepCopy() *MPIJobSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MPIJobTemplate) DeepCopyInto(out *MPIJobTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobTemplate.
+func (in *MPIJobTemplate) DeepCopy() *MPIJobTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(MPIJobTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MPIJobTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MPIJobTemplateList) DeepCopyInto(out *MPIJobTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MPIJobTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobTemplateList.
+func (in *MPIJobTemplateList) DeepCopy() *MPIJobTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(MPIJobTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MPIJobTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MPIJobTemplateSpec) DeepCopyInto(out *MPIJobTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MPIJobTemplateSpec.
+func (in *MPIJobTemplateSpec) DeepCopy() *MPIJobTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MPIJobTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPolicy) DeepCopyInto(out *MetadataPolicy) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPolicy.
+func (in *MetadataPolicy) DeepCopy() *MetadataPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterSet) DeepCopyInto(out *ParameterSet) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterSet.
+func (in *ParameterSet) DeepCopy() *ParameterSet {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Profiling) DeepCopyInto(out *Profiling) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Profiling.
+func (in *Profiling) DeepCopy() *Profiling {
+	if in == nil {
+		return nil
+	}
+	out := new(Profiling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequeuePolicy) DeepCopyInto(out *RequeuePolicy) {
+	*out = *in
+	if in.BackoffSeconds != nil {
+		in, out := &in.BackoffSeconds, &out.BackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequeuePolicy.
+func (in *RequeuePolicy) DeepCopy() *RequeuePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RequeuePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHOptions) DeepCopyInto(out *SSHOptions) {
+	*out = *in
+	if in.ConnectionAttempts != nil {
+		in, out := &in.ConnectionAttempts, &out.ConnectionAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ServerAliveInterval != nil {
+		in, out := &in.ServerAliveInterval, &out.ServerAliveInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ServerAliveCountMax != nil {
+		in, out := &in.ServerAliveCountMax, &out.ServerAliveCountMax
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Ciphers != nil {
+		in, out := &in.Ciphers, &out.Ciphers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHOptions.
+func (in *SSHOptions) DeepCopy() *SSHOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHOptions)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -26,11 +26,24 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&CronMPIJob{}, func(obj interface{}) { SetObjectDefaults_CronMPIJob(obj.(*CronMPIJob)) })
+	scheme.AddTypeDefaultingFunc(&CronMPIJobList{}, func(obj interface{}) { SetObjectDefaults_CronMPIJobList(obj.(*CronMPIJobList)) })
 	scheme.AddTypeDefaultingFunc(&MPIJob{}, func(obj interface{}) { SetObjectDefaults_MPIJob(obj.(*MPIJob)) })
 	scheme.AddTypeDefaultingFunc(&MPIJobList{}, func(obj interface{}) { SetObjectDefaults_MPIJobList(obj.(*MPIJobList)) })
 	return nil
 }
 
+func SetObjectDefaults_CronMPIJob(in *CronMPIJob) {
+	SetDefaults_CronMPIJob(in)
+}
+
+func SetObjectDefaults_CronMPIJobList(in *CronMPIJobList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_CronMPIJob(a)
+	}
+}
+
 func SetObjectDefaults_MPIJob(in *MPIJob) {
 	SetDefaults_MPIJob(in)
 }
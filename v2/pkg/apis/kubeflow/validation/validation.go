@@ -16,9 +16,13 @@ package validation
 
 import (
 	"fmt"
+	"path"
+	"sort"
 	"strings"
 
+	"github.com/robfig/cron/v3"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/sets"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -35,17 +39,24 @@ var (
 
 	validMPIImplementations = sets.NewString(
 		string(kubeflow.MPIImplementationOpenMPI),
-		string(kubeflow.MPIImplementationIntel))
+		string(kubeflow.MPIImplementationIntel),
+		string(kubeflow.MPIImplementationCharm))
 
 	validRestartPolicies = sets.NewString(
 		string(common.RestartPolicyNever),
 		string(common.RestartPolicyOnFailure),
 	)
+
+	validConcurrencyPolicies = sets.NewString(
+		string(kubeflow.AllowConcurrent),
+		string(kubeflow.ForbidConcurrent),
+		string(kubeflow.ReplaceConcurrent))
 )
 
 func ValidateMPIJob(job *kubeflow.MPIJob) field.ErrorList {
 	errs := validateMPIJobName(job)
 	errs = append(errs, validateMPIJobSpec(&job.Spec, field.NewPath("spec"))...)
+	errs = append(errs, validateDependsOn(job, field.NewPath("spec", "dependsOn"))...)
 	return errs
 }
 
@@ -57,13 +68,91 @@ func validateMPIJobName(job *kubeflow.MPIJob) field.ErrorList {
 			replicas = *workerSpec.Replicas
 		}
 	}
-	maximumPodHostname := fmt.Sprintf("%s-worker-%d", job.Name, replicas-1)
+	// The name is the value of the job-name label of the pods.
+	if errs := apimachineryvalidation.IsValidLabelValue(job.Name); len(errs) > 0 {
+		return append(allErrs, field.Invalid(field.NewPath("metadata").Child("name"), job.ObjectMeta.Name, fmt.Sprintf("will not able to label pods: %s", strings.Join(errs, ", "))))
+	}
+	prefix := kubeflow.ChildNamePrefix(job.Name)
+	maximumPodHostname := fmt.Sprintf("%s-worker-%d", prefix, replicas-1)
 	if errs := apimachineryvalidation.IsDNS1123Label(maximumPodHostname); len(errs) > 0 {
-		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata").Child("name"), job.ObjectMeta.Name, fmt.Sprintf("will not able to create pod with invalid DNS label %q: %s", maximumPodHostname, strings.Join(errs, ", "))))
+		return append(allErrs, field.Invalid(field.NewPath("metadata").Child("name"), job.ObjectMeta.Name, fmt.Sprintf("will not able to create pod with invalid DNS label %q: %s", maximumPodHostname, strings.Join(errs, ", "))))
+	}
+	// Service names are DNS-1035 labels, which can't start with a digit.
+	for _, svcName := range []string{prefix + "-worker", prefix + "-launcher"} {
+		if errs := apimachineryvalidation.IsDNS1035Label(svcName); len(errs) > 0 {
+			return append(allErrs, field.Invalid(field.NewPath("metadata").Child("name"), job.ObjectMeta.Name, fmt.Sprintf("will not able to create service with invalid DNS label %q: %s", svcName, strings.Join(errs, ", "))))
+		}
 	}
 	return allErrs
 }
 
+// ValidateCronMPIJob validates the scheduling fields of a CronMPIJob. The job
+// template is validated on the MPIJobs created from it.
+func ValidateCronMPIJob(cronJob *kubeflow.CronMPIJob) field.ErrorList {
+	var errs field.ErrorList
+	path := field.NewPath("spec")
+	if _, err := cron.ParseStandard(cronJob.Spec.Schedule); err != nil {
+		errs = append(errs, field.Invalid(path.Child("schedule"), cronJob.Spec.Schedule, err.Error()))
+	}
+	if !validConcurrencyPolicies.Has(string(cronJob.Spec.ConcurrencyPolicy)) {
+		errs = append(errs, field.NotSupported(path.Child("concurrencyPolicy"), cronJob.Spec.ConcurrencyPolicy, validConcurrencyPolicies.List()))
+	}
+	if cronJob.Spec.StartingDeadlineSeconds != nil {
+		errs = append(errs, apivalidation.ValidateNonnegativeField(*cronJob.Spec.StartingDeadlineSeconds, path.Child("startingDeadlineSeconds"))...)
+	}
+	if cronJob.Spec.SuccessfulJobsHistoryLimit != nil {
+		errs = append(errs, apivalidation.ValidateNonnegativeField(int64(*cronJob.Spec.SuccessfulJobsHistoryLimit), path.Child("successfulJobsHistoryLimit"))...)
+	}
+	if cronJob.Spec.FailedJobsHistoryLimit != nil {
+		errs = append(errs, apivalidation.ValidateNonnegativeField(int64(*cronJob.Spec.FailedJobsHistoryLimit), path.Child("failedJobsHistoryLimit"))...)
+	}
+	return errs
+}
+
+// ValidateMPIJobArray validates the parameter sets of an MPIJobArray. The job
+// template is validated on the MPIJobs created from it.
+func ValidateMPIJobArray(array *kubeflow.MPIJobArray) field.ErrorList {
+	var errs field.ErrorList
+	path := field.NewPath("spec", "parameters")
+	if len(array.Spec.Parameters) == 0 {
+		errs = append(errs, field.Required(path, "must have at least one parameter set"))
+	}
+	for i, params := range array.Spec.Parameters {
+		envPath := path.Index(i).Child("env")
+		seen := sets.NewString()
+		for j, env := range params.Env {
+			if seen.Has(env.Name) {
+				errs = append(errs, field.Duplicate(envPath.Index(j).Child("name"), env.Name))
+			}
+			seen.Insert(env.Name)
+			for _, msg := range apimachineryvalidation.IsEnvVarName(env.Name) {
+				errs = append(errs, field.Invalid(envPath.Index(j).Child("name"), env.Name, msg))
+			}
+		}
+	}
+	return errs
+}
+
+// validateDependsOn only rejects a job that depends on itself. Cycles through
+// other MPIJobs are detected by the controller when the job is synced.
+func validateDependsOn(job *kubeflow.MPIJob, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, name := range job.Spec.DependsOn {
+		if name == job.Name {
+			errs = append(errs, field.Invalid(path.Index(i), name, "must not depend on itself"))
+		} else if seen.Has(name) {
+			errs = append(errs, field.Duplicate(path.Index(i), name))
+		} else {
+			for _, msg := range apivalidation.NameIsDNSSubdomain(name, false) {
+				errs = append(errs, field.Invalid(path.Index(i), name, msg))
+			}
+		}
+		seen.Insert(name)
+	}
+	return errs
+}
+
 func validateMPIJobSpec(spec *kubeflow.MPIJobSpec, path *field.Path) field.ErrorList {
 	errs := validateMPIReplicaSpecs(spec.MPIReplicaSpecs, path.Child("mpiReplicaSpecs"))
 	if spec.SlotsPerWorker == nil {
@@ -72,12 +161,54 @@ func validateMPIJobSpec(spec *kubeflow.MPIJobSpec, path *field.Path) field.Error
 		errs = append(errs, apivalidation.ValidateNonnegativeField(int64(*spec.SlotsPerWorker), path.Child("slotsPerWorker"))...)
 	}
 	errs = append(errs, validateRunPolicy(&spec.RunPolicy, path.Child("runPolicy"))...)
+	if p := spec.RequeueOnFailure; p != nil {
+		errs = append(errs, apivalidation.ValidateNonnegativeField(int64(p.MaxRequeues), path.Child("requeueOnFailure", "maxRequeues"))...)
+		if p.BackoffSeconds != nil {
+			errs = append(errs, apivalidation.ValidateNonnegativeField(int64(*p.BackoffSeconds), path.Child("requeueOnFailure", "backoffSeconds"))...)
+		}
+	}
+	if spec.DeadlineSeconds != nil && *spec.DeadlineSeconds < 1 {
+		errs = append(errs, field.Invalid(path.Child("deadlineSeconds"), *spec.DeadlineSeconds, "must be greater than or equal to 1"))
+	}
 	if spec.SSHAuthMountPath == "" {
 		errs = append(errs, field.Required(path.Child("sshAuthMountPath"), "must have a mount path for SSH credentials"))
 	}
+	if spec.SSHOptions != nil {
+		errs = append(errs, validateSSHOptions(spec.SSHOptions, path.Child("sshOptions"))...)
+	}
 	if !validMPIImplementations.Has(string(spec.MPIImplementation)) {
 		errs = append(errs, field.NotSupported(path.Child("mpiImplementation"), spec.MPIImplementation, validMPIImplementations.List()))
 	}
+	if p := spec.MetadataPolicy; p != nil {
+		errs = append(errs, metav1validation.ValidateLabels(p.Labels, path.Child("metadataPolicy", "labels"))...)
+		errs = append(errs, apivalidation.ValidateAnnotations(p.Annotations, path.Child("metadataPolicy", "annotations"))...)
+	}
+	if spec.Profiling != nil {
+		errs = append(errs, validateProfiling(spec.Profiling, path.Child("profiling"))...)
+	}
+	return errs
+}
+
+func validateProfiling(p *kubeflow.Profiling, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	names := make([]string, 0, len(p.Env))
+	for name := range p.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, msg := range apimachineryvalidation.IsEnvVarName(name) {
+			errs = append(errs, field.Invalid(fldPath.Child("env").Key(name), name, msg))
+		}
+	}
+	if p.OutputClaimName != "" {
+		for _, msg := range apivalidation.NameIsDNSSubdomain(p.OutputClaimName, false) {
+			errs = append(errs, field.Invalid(fldPath.Child("outputClaimName"), p.OutputClaimName, msg))
+		}
+	}
+	if p.OutputPath != "" && !path.IsAbs(p.OutputPath) {
+		errs = append(errs, field.Invalid(fldPath.Child("outputPath"), p.OutputPath, "must be an absolute path"))
+	}
 	return errs
 }
 
@@ -101,10 +232,40 @@ func validateRunPolicy(policy *common.RunPolicy, path *field.Path) field.ErrorLi
 	return errs
 }
 
+func validateSSHOptions(opts *kubeflow.SSHOptions, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if opts.ConnectionAttempts != nil && *opts.ConnectionAttempts < 1 {
+		errs = append(errs, field.Invalid(path.Child("connectionAttempts"), *opts.ConnectionAttempts, "must be greater than or equal to 1"))
+	}
+	if opts.ServerAliveInterval != nil {
+		errs = append(errs, apivalidation.ValidateNonnegativeField(int64(*opts.ServerAliveInterval), path.Child("serverAliveInterval"))...)
+	}
+	if opts.ServerAliveCountMax != nil && *opts.ServerAliveCountMax < 1 {
+		errs = append(errs, field.Invalid(path.Child("serverAliveCountMax"), *opts.ServerAliveCountMax, "must be greater than or equal to 1"))
+	}
+	for i, c := range opts.Ciphers {
+		// The ciphers are joined into a single ssh option.
+		if c == "" || strings.ContainsAny(c, ", \t\n") {
+			errs = append(errs, field.Invalid(path.Child("ciphers").Index(i), c, "must be a non-empty cipher name without commas or spaces"))
+		}
+	}
+	if strings.ContainsAny(opts.ProxyJump, " \t\n") {
+		errs = append(errs, field.Invalid(path.Child("proxyJump"), opts.ProxyJump, "must not contain spaces"))
+	} else if opts.ProxyJump != "" {
+		for _, h := range strings.Split(opts.ProxyJump, ",") {
+			if h == "" {
+				errs = append(errs, field.Invalid(path.Child("proxyJump"), opts.ProxyJump, "must not contain empty jump hosts"))
+				break
+			}
+		}
+	}
+	return errs
+}
+
 func validateMPIReplicaSpecs(replicaSpecs map[kubeflow.MPIReplicaType]*common.ReplicaSpec, path *field.Path) field.ErrorList {
 	var errs field.ErrorList
 	if replicaSpecs == nil {
-		errs = append(errs, field.Required(path, "must have replica specs"))
+		errs = append(errs, field.Required(path, "must have replica specs, in the MPIJob or in its MPIJobTemplate"))
 		return errs
 	}
 	errs = append(errs, validateLauncherReplicaSpec(replicaSpecs[kubeflow.MPIReplicaTypeLauncher], path.Key(string(kubeflow.MPIReplicaTypeLauncher)))...)
@@ -126,6 +126,7 @@ func TestValidateMPIJob(t *testing.T) {
 					Name: "this-name-is-waaaaaaaay-too-long-for-a-worker-hostname",
 				},
 				Spec: v2beta1.MPIJobSpec{
+					DependsOn:      []string{"this-name-is-waaaaaaaay-too-long-for-a-worker-hostname", "bar", "bar", "Bar"},
 					SlotsPerWorker: newInt32(2),
 					RunPolicy: common.RunPolicy{
 						CleanPodPolicy:          newCleanPodPolicy("unknown"),
@@ -133,8 +134,29 @@ func TestValidateMPIJob(t *testing.T) {
 						ActiveDeadlineSeconds:   newInt64(-1),
 						BackoffLimit:            newInt32(-1),
 					},
-					SSHAuthMountPath:  "/root/.ssh",
+					RequeueOnFailure: &v2beta1.RequeuePolicy{
+						MaxRequeues:    -1,
+						BackoffSeconds: newInt32(-1),
+					},
+					DeadlineSeconds:  newInt64(0),
+					SSHAuthMountPath: "/root/.ssh",
+					SSHOptions: &v2beta1.SSHOptions{
+						ConnectionAttempts:  newInt32(0),
+						ServerAliveInterval: newInt32(-1),
+						ServerAliveCountMax: newInt32(0),
+						Ciphers:             []string{"aes128-ctr", "aes256-ctr,3des-cbc"},
+						ProxyJump:           "bastion,,gw",
+					},
 					MPIImplementation: v2beta1.MPIImplementation("Unknown"),
+					MetadataPolicy: &v2beta1.MetadataPolicy{
+						Labels:      map[string]string{"team": "a b"},
+						Annotations: map[string]string{"-invalid": "x"},
+					},
+					Profiling: &v2beta1.Profiling{
+						Env:             map[string]string{"LD_PRELOAD": "/usr/lib/libmpiP.so", "1=2": "x"},
+						OutputClaimName: "Results",
+						OutputPath:      "profile",
+					},
 					MPIReplicaSpecs: map[v2beta1.MPIReplicaType]*common.ReplicaSpec{
 						v2beta1.MPIReplicaTypeLauncher: {
 							Replicas:      newInt32(1),
@@ -178,10 +200,191 @@ func TestValidateMPIJob(t *testing.T) {
 					Type:  field.ErrorTypeInvalid,
 					Field: "spec.runPolicy.backoffLimit",
 				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.requeueOnFailure.maxRequeues",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.requeueOnFailure.backoffSeconds",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.deadlineSeconds",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.sshOptions.connectionAttempts",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.sshOptions.serverAliveInterval",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.sshOptions.serverAliveCountMax",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.sshOptions.ciphers[1]",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.sshOptions.proxyJump",
+				},
 				{
 					Type:  field.ErrorTypeNotSupported,
 					Field: "spec.mpiImplementation",
 				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.metadataPolicy.labels",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.metadataPolicy.annotations",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.profiling.env[1=2]",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.profiling.outputClaimName",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.profiling.outputPath",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.dependsOn[0]",
+				},
+				{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "spec.dependsOn[2]",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.dependsOn[3]",
+				},
+			},
+		},
+		"name starting with digit": {
+			job: v2beta1.MPIJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "1foo",
+				},
+				Spec: v2beta1.MPIJobSpec{
+					SlotsPerWorker: newInt32(2),
+					RunPolicy: common.RunPolicy{
+						CleanPodPolicy: newCleanPodPolicy(common.CleanPodPolicyRunning),
+					},
+					SSHAuthMountPath:  "/home/mpiuser/.ssh",
+					MPIImplementation: v2beta1.MPIImplementationIntel,
+					MPIReplicaSpecs: map[v2beta1.MPIReplicaType]*common.ReplicaSpec{
+						v2beta1.MPIReplicaTypeLauncher: {
+							Replicas:      newInt32(1),
+							RestartPolicy: common.RestartPolicyOnFailure,
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{}},
+								},
+							},
+						},
+						v2beta1.MPIReplicaTypeWorker: {
+							Replicas:      newInt32(3),
+							RestartPolicy: common.RestartPolicyNever,
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErrs: field.ErrorList{
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "metadata.name",
+				},
+			},
+		},
+		"shortened name": {
+			job: v2beta1.MPIJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "this-name-is-shortened-to-leave-room-for-many-worker-hostnames",
+				},
+				Spec: v2beta1.MPIJobSpec{
+					SlotsPerWorker: newInt32(2),
+					RunPolicy: common.RunPolicy{
+						CleanPodPolicy: newCleanPodPolicy(common.CleanPodPolicyRunning),
+					},
+					SSHAuthMountPath:  "/home/mpiuser/.ssh",
+					MPIImplementation: v2beta1.MPIImplementationIntel,
+					MPIReplicaSpecs: map[v2beta1.MPIReplicaType]*common.ReplicaSpec{
+						v2beta1.MPIReplicaTypeLauncher: {
+							Replicas:      newInt32(1),
+							RestartPolicy: common.RestartPolicyOnFailure,
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{}},
+								},
+							},
+						},
+						v2beta1.MPIReplicaTypeWorker: {
+							Replicas:      newInt32(1000),
+							RestartPolicy: common.RestartPolicyNever,
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"name too long for a label value": {
+			job: v2beta1.MPIJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "this-name-is-too-long-to-be-the-value-of-the-job-name-label-of-pods",
+				},
+				Spec: v2beta1.MPIJobSpec{
+					SlotsPerWorker: newInt32(2),
+					RunPolicy: common.RunPolicy{
+						CleanPodPolicy: newCleanPodPolicy(common.CleanPodPolicyRunning),
+					},
+					SSHAuthMountPath:  "/home/mpiuser/.ssh",
+					MPIImplementation: v2beta1.MPIImplementationIntel,
+					MPIReplicaSpecs: map[v2beta1.MPIReplicaType]*common.ReplicaSpec{
+						v2beta1.MPIReplicaTypeLauncher: {
+							Replicas:      newInt32(1),
+							RestartPolicy: common.RestartPolicyOnFailure,
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{}},
+								},
+							},
+						},
+						v2beta1.MPIReplicaTypeWorker: {
+							Replicas:      newInt32(3),
+							RestartPolicy: common.RestartPolicyNever,
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{}},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErrs: field.ErrorList{
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "metadata.name",
+				},
 			},
 		},
 		"empty replica specs": {
@@ -206,6 +409,27 @@ func TestValidateMPIJob(t *testing.T) {
 				},
 			},
 		},
+		"no replica specs nor template": {
+			job: v2beta1.MPIJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: v2beta1.MPIJobSpec{
+					SlotsPerWorker: newInt32(2),
+					RunPolicy: common.RunPolicy{
+						CleanPodPolicy: newCleanPodPolicy(common.CleanPodPolicyRunning),
+					},
+					SSHAuthMountPath:  "/root/.ssh",
+					MPIImplementation: v2beta1.MPIImplementationOpenMPI,
+				},
+			},
+			wantErrs: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeRequired,
+					Field: "spec.mpiReplicaSpecs",
+				},
+			},
+		},
 		"missing replica spec fields": {
 			job: v2beta1.MPIJob{
 				ObjectMeta: metav1.ObjectMeta{
@@ -315,6 +539,120 @@ func TestValidateMPIJob(t *testing.T) {
 	}
 }
 
+func TestValidateCronMPIJob(t *testing.T) {
+	cases := map[string]struct {
+		job      v2beta1.CronMPIJob
+		wantErrs field.ErrorList
+	}{
+		"valid": {
+			job: v2beta1.CronMPIJob{
+				Spec: v2beta1.CronMPIJobSpec{
+					Schedule:                   "*/10 * * * *",
+					ConcurrencyPolicy:          v2beta1.ForbidConcurrent,
+					StartingDeadlineSeconds:    newInt64(60),
+					SuccessfulJobsHistoryLimit: newInt32(3),
+					FailedJobsHistoryLimit:     newInt32(0),
+				},
+			},
+		},
+		"invalid fields": {
+			job: v2beta1.CronMPIJob{
+				Spec: v2beta1.CronMPIJobSpec{
+					Schedule:                   "* * *",
+					ConcurrencyPolicy:          v2beta1.ConcurrencyPolicy("Unknown"),
+					StartingDeadlineSeconds:    newInt64(-1),
+					SuccessfulJobsHistoryLimit: newInt32(-1),
+					FailedJobsHistoryLimit:     newInt32(-1),
+				},
+			},
+			wantErrs: field.ErrorList{
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.schedule",
+				},
+				{
+					Type:  field.ErrorTypeNotSupported,
+					Field: "spec.concurrencyPolicy",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.startingDeadlineSeconds",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.successfulJobsHistoryLimit",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.failedJobsHistoryLimit",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ValidateCronMPIJob(&tc.job)
+			if diff := cmp.Diff(tc.wantErrs, got, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("Unexpected errors (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateMPIJobArray(t *testing.T) {
+	cases := map[string]struct {
+		array    v2beta1.MPIJobArray
+		wantErrs field.ErrorList
+	}{
+		"valid": {
+			array: v2beta1.MPIJobArray{
+				Spec: v2beta1.MPIJobArraySpec{
+					Parameters: []v2beta1.ParameterSet{
+						{Env: []corev1.EnvVar{{Name: "LEARNING_RATE", Value: "0.1"}}},
+						{Env: []corev1.EnvVar{{Name: "LEARNING_RATE", Value: "0.01"}}},
+					},
+				},
+			},
+		},
+		"no parameters": {
+			wantErrs: field.ErrorList{
+				{
+					Type:  field.ErrorTypeRequired,
+					Field: "spec.parameters",
+				},
+			},
+		},
+		"invalid env": {
+			array: v2beta1.MPIJobArray{
+				Spec: v2beta1.MPIJobArraySpec{
+					Parameters: []v2beta1.ParameterSet{
+						{Env: []corev1.EnvVar{{Name: "SEED"}, {Name: "SEED"}}},
+						{Env: []corev1.EnvVar{{Name: "1=2"}}},
+					},
+				},
+			},
+			wantErrs: field.ErrorList{
+				{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "spec.parameters[0].env[1].name",
+				},
+				{
+					Type:  field.ErrorTypeInvalid,
+					Field: "spec.parameters[1].env[0].name",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ValidateMPIJobArray(&tc.array)
+			if diff := cmp.Diff(tc.wantErrs, got, cmpopts.IgnoreFields(field.Error{}, "Detail", "BadValue")); diff != "" {
+				t.Errorf("Unexpected errors (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func newInt32(v int32) *int32 {
 	return &v
 }
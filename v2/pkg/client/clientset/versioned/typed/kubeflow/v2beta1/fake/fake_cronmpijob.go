@@ -0,0 +1,140 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeCronMPIJobs implements CronMPIJobInterface
+type FakeCronMPIJobs struct {
+	Fake *FakeKubeflowV2beta1
+	ns   string
+}
+
+var cronmpijobsResource = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v2beta1", Resource: "cronmpijobs"}
+
+var cronmpijobsKind = schema.GroupVersionKind{Group: "kubeflow.org", Version: "v2beta1", Kind: "CronMPIJob"}
+
+// Get takes name of the cronMPIJob, and returns the corresponding cronMPIJob object, and an error if there is any.
+func (c *FakeCronMPIJobs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2beta1.CronMPIJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(cronmpijobsResource, c.ns, name), &v2beta1.CronMPIJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.CronMPIJob), err
+}
+
+// List takes label and field selectors, and returns the list of CronMPIJobs that match those selectors.
+func (c *FakeCronMPIJobs) List(ctx context.Context, opts v1.ListOptions) (result *v2beta1.CronMPIJobList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(cronmpijobsResource, cronmpijobsKind, c.ns, opts), &v2beta1.CronMPIJobList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v2beta1.CronMPIJobList{ListMeta: obj.(*v2beta1.CronMPIJobList).ListMeta}
+	for _, item := range obj.(*v2beta1.CronMPIJobList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested cronMPIJobs.
+func (c *FakeCronMPIJobs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(cronmpijobsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a cronMPIJob and creates it.  Returns the server's representation of the cronMPIJob, and an error, if there is any.
+func (c *FakeCronMPIJobs) Create(ctx context.Context, cronMPIJob *v2beta1.CronMPIJob, opts v1.CreateOptions) (result *v2beta1.CronMPIJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(cronmpijobsResource, c.ns, cronMPIJob), &v2beta1.CronMPIJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.CronMPIJob), err
+}
+
+// Update takes the representation of a cronMPIJob and updates it. Returns the server's representation of the cronMPIJob, and an error, if there is any.
+func (c *FakeCronMPIJobs) Update(ctx context.Context, cronMPIJob *v2beta1.CronMPIJob, opts v1.UpdateOptions) (result *v2beta1.CronMPIJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(cronmpijobsResource, c.ns, cronMPIJob), &v2beta1.CronMPIJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.CronMPIJob), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeCronMPIJobs) UpdateStatus(ctx context.Context, cronMPIJob *v2beta1.CronMPIJob, opts v1.UpdateOptions) (*v2beta1.CronMPIJob, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(cronmpijobsResource, "status", c.ns, cronMPIJob), &v2beta1.CronMPIJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.CronMPIJob), err
+}
+
+// Delete takes name of the cronMPIJob and deletes it. Returns an error if one occurs.
+func (c *FakeCronMPIJobs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(cronmpijobsResource, c.ns, name), &v2beta1.CronMPIJob{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeCronMPIJobs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(cronmpijobsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v2beta1.CronMPIJobList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched cronMPIJob.
+func (c *FakeCronMPIJobs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2beta1.CronMPIJob, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(cronmpijobsResource, c.ns, name, pt, data, subresources...), &v2beta1.CronMPIJob{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.CronMPIJob), err
+}
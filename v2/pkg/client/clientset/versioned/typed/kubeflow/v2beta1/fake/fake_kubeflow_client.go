@@ -26,10 +26,22 @@ type FakeKubeflowV2beta1 struct {
 	*testing.Fake
 }
 
+func (c *FakeKubeflowV2beta1) CronMPIJobs(namespace string) v2beta1.CronMPIJobInterface {
+	return &FakeCronMPIJobs{c, namespace}
+}
+
 func (c *FakeKubeflowV2beta1) MPIJobs(namespace string) v2beta1.MPIJobInterface {
 	return &FakeMPIJobs{c, namespace}
 }
 
+func (c *FakeKubeflowV2beta1) MPIJobArrays(namespace string) v2beta1.MPIJobArrayInterface {
+	return &FakeMPIJobArrays{c, namespace}
+}
+
+func (c *FakeKubeflowV2beta1) MPIJobTemplates(namespace string) v2beta1.MPIJobTemplateInterface {
+	return &FakeMPIJobTemplates{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeKubeflowV2beta1) RESTClient() rest.Interface {
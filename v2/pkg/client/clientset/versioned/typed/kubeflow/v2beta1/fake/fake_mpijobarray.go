@@ -0,0 +1,140 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeMPIJobArrays implements MPIJobArrayInterface
+type FakeMPIJobArrays struct {
+	Fake *FakeKubeflowV2beta1
+	ns   string
+}
+
+var mpijobarraysResource = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v2beta1", Resource: "mpijobarrays"}
+
+var mpijobarraysKind = schema.GroupVersionKind{Group: "kubeflow.org", Version: "v2beta1", Kind: "MPIJobArray"}
+
+// Get takes name of the mPIJobArray, and returns the corresponding mPIJobArray object, and an error if there is any.
+func (c *FakeMPIJobArrays) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2beta1.MPIJobArray, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(mpijobarraysResource, c.ns, name), &v2beta1.MPIJobArray{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobArray), err
+}
+
+// List takes label and field selectors, and returns the list of MPIJobArrays that match those selectors.
+func (c *FakeMPIJobArrays) List(ctx context.Context, opts v1.ListOptions) (result *v2beta1.MPIJobArrayList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(mpijobarraysResource, mpijobarraysKind, c.ns, opts), &v2beta1.MPIJobArrayList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v2beta1.MPIJobArrayList{ListMeta: obj.(*v2beta1.MPIJobArrayList).ListMeta}
+	for _, item := range obj.(*v2beta1.MPIJobArrayList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested mPIJobArrays.
+func (c *FakeMPIJobArrays) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(mpijobarraysResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a mPIJobArray and creates it.  Returns the server's representation of the mPIJobArray, and an error, if there is any.
+func (c *FakeMPIJobArrays) Create(ctx context.Context, mPIJobArray *v2beta1.MPIJobArray, opts v1.CreateOptions) (result *v2beta1.MPIJobArray, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(mpijobarraysResource, c.ns, mPIJobArray), &v2beta1.MPIJobArray{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobArray), err
+}
+
+// Update takes the representation of a mPIJobArray and updates it. Returns the server's representation of the mPIJobArray, and an error, if there is any.
+func (c *FakeMPIJobArrays) Update(ctx context.Context, mPIJobArray *v2beta1.MPIJobArray, opts v1.UpdateOptions) (result *v2beta1.MPIJobArray, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(mpijobarraysResource, c.ns, mPIJobArray), &v2beta1.MPIJobArray{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobArray), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeMPIJobArrays) UpdateStatus(ctx context.Context, mPIJobArray *v2beta1.MPIJobArray, opts v1.UpdateOptions) (*v2beta1.MPIJobArray, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(mpijobarraysResource, "status", c.ns, mPIJobArray), &v2beta1.MPIJobArray{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobArray), err
+}
+
+// Delete takes name of the mPIJobArray and deletes it. Returns an error if one occurs.
+func (c *FakeMPIJobArrays) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(mpijobarraysResource, c.ns, name), &v2beta1.MPIJobArray{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMPIJobArrays) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(mpijobarraysResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v2beta1.MPIJobArrayList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched mPIJobArray.
+func (c *FakeMPIJobArrays) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2beta1.MPIJobArray, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(mpijobarraysResource, c.ns, name, pt, data, subresources...), &v2beta1.MPIJobArray{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobArray), err
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeMPIJobTemplates implements MPIJobTemplateInterface
+type FakeMPIJobTemplates struct {
+	Fake *FakeKubeflowV2beta1
+	ns   string
+}
+
+var mpijobtemplatesResource = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v2beta1", Resource: "mpijobtemplates"}
+
+var mpijobtemplatesKind = schema.GroupVersionKind{Group: "kubeflow.org", Version: "v2beta1", Kind: "MPIJobTemplate"}
+
+// Get takes name of the mPIJobTemplate, and returns the corresponding mPIJobTemplate object, and an error if there is any.
+func (c *FakeMPIJobTemplates) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2beta1.MPIJobTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(mpijobtemplatesResource, c.ns, name), &v2beta1.MPIJobTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobTemplate), err
+}
+
+// List takes label and field selectors, and returns the list of MPIJobTemplates that match those selectors.
+func (c *FakeMPIJobTemplates) List(ctx context.Context, opts v1.ListOptions) (result *v2beta1.MPIJobTemplateList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(mpijobtemplatesResource, mpijobtemplatesKind, c.ns, opts), &v2beta1.MPIJobTemplateList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v2beta1.MPIJobTemplateList{ListMeta: obj.(*v2beta1.MPIJobTemplateList).ListMeta}
+	for _, item := range obj.(*v2beta1.MPIJobTemplateList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested mPIJobTemplates.
+func (c *FakeMPIJobTemplates) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(mpijobtemplatesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a mPIJobTemplate and creates it.  Returns the server's representation of the mPIJobTemplate, and an error, if there is any.
+func (c *FakeMPIJobTemplates) Create(ctx context.Context, mPIJobTemplate *v2beta1.MPIJobTemplate, opts v1.CreateOptions) (result *v2beta1.MPIJobTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(mpijobtemplatesResource, c.ns, mPIJobTemplate), &v2beta1.MPIJobTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobTemplate), err
+}
+
+// Update takes the representation of a mPIJobTemplate and updates it. Returns the server's representation of the mPIJobTemplate, and an error, if there is any.
+func (c *FakeMPIJobTemplates) Update(ctx context.Context, mPIJobTemplate *v2beta1.MPIJobTemplate, opts v1.UpdateOptions) (result *v2beta1.MPIJobTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(mpijobtemplatesResource, c.ns, mPIJobTemplate), &v2beta1.MPIJobTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobTemplate), err
+}
+
+// Delete takes name of the mPIJobTemplate and deletes it. Returns an error if one occurs.
+func (c *FakeMPIJobTemplates) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(mpijobtemplatesResource, c.ns, name), &v2beta1.MPIJobTemplate{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMPIJobTemplates) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(mpijobtemplatesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v2beta1.MPIJobTemplateList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched mPIJobTemplate.
+func (c *FakeMPIJobTemplates) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2beta1.MPIJobTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(mpijobtemplatesResource, c.ns, name, pt, data, subresources...), &v2beta1.MPIJobTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v2beta1.MPIJobTemplate), err
+}
@@ -16,4 +16,10 @@
 
 package v2beta1
 
+type CronMPIJobExpansion interface{}
+
 type MPIJobExpansion interface{}
+
+type MPIJobArrayExpansion interface{}
+
+type MPIJobTemplateExpansion interface{}
@@ -24,7 +24,10 @@ import (
 
 type KubeflowV2beta1Interface interface {
 	RESTClient() rest.Interface
+	CronMPIJobsGetter
 	MPIJobsGetter
+	MPIJobArraysGetter
+	MPIJobTemplatesGetter
 }
 
 // KubeflowV2beta1Client is used to interact with features provided by the kubeflow.org group.
@@ -32,10 +35,22 @@ type KubeflowV2beta1Client struct {
 	restClient rest.Interface
 }
 
+func (c *KubeflowV2beta1Client) CronMPIJobs(namespace string) CronMPIJobInterface {
+	return newCronMPIJobs(c, namespace)
+}
+
 func (c *KubeflowV2beta1Client) MPIJobs(namespace string) MPIJobInterface {
 	return newMPIJobs(c, namespace)
 }
 
+func (c *KubeflowV2beta1Client) MPIJobArrays(namespace string) MPIJobArrayInterface {
+	return newMPIJobArrays(c, namespace)
+}
+
+func (c *KubeflowV2beta1Client) MPIJobTemplates(namespace string) MPIJobTemplateInterface {
+	return newMPIJobTemplates(c, namespace)
+}
+
 // NewForConfig creates a new KubeflowV2beta1Client for the given config.
 func NewForConfig(c *rest.Config) (*KubeflowV2beta1Client, error) {
 	config := *c
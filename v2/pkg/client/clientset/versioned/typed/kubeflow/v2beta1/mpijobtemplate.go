@@ -0,0 +1,176 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v2beta1
+
+import (
+	"context"
+	"time"
+
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	scheme "github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// MPIJobTemplatesGetter has a method to return a MPIJobTemplateInterface.
+// A group's client should implement this interface.
+type MPIJobTemplatesGetter interface {
+	MPIJobTemplates(namespace string) MPIJobTemplateInterface
+}
+
+// MPIJobTemplateInterface has methods to work with MPIJobTemplate resources.
+type MPIJobTemplateInterface interface {
+	Create(ctx context.Context, mPIJobTemplate *v2beta1.MPIJobTemplate, opts v1.CreateOptions) (*v2beta1.MPIJobTemplate, error)
+	Update(ctx context.Context, mPIJobTemplate *v2beta1.MPIJobTemplate, opts v1.UpdateOptions) (*v2beta1.MPIJobTemplate, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v2beta1.MPIJobTemplate, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v2beta1.MPIJobTemplateList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2beta1.MPIJobTemplate, err error)
+	MPIJobTemplateExpansion
+}
+
+// mPIJobTemplates implements MPIJobTemplateInterface
+type mPIJobTemplates struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMPIJobTemplates returns a MPIJobTemplates
+func newMPIJobTemplates(c *KubeflowV2beta1Client, namespace string) *mPIJobTemplates {
+	return &mPIJobTemplates{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the mPIJobTemplate, and returns the corresponding mPIJobTemplate object, and an error if there is any.
+func (c *mPIJobTemplates) Get(ctx context.Context, name string, options v1.GetOptions) (result *v2beta1.MPIJobTemplate, err error) {
+	result = &v2beta1.MPIJobTemplate{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MPIJobTemplates that match those selectors.
+func (c *mPIJobTemplates) List(ctx context.Context, opts v1.ListOptions) (result *v2beta1.MPIJobTemplateList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v2beta1.MPIJobTemplateList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested mPIJobTemplates.
+func (c *mPIJobTemplates) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a mPIJobTemplate and creates it.  Returns the server's representation of the mPIJobTemplate, and an error, if there is any.
+func (c *mPIJobTemplates) Create(ctx context.Context, mPIJobTemplate *v2beta1.MPIJobTemplate, opts v1.CreateOptions) (result *v2beta1.MPIJobTemplate, err error) {
+	result = &v2beta1.MPIJobTemplate{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(mPIJobTemplate).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a mPIJobTemplate and updates it. Returns the server's representation of the mPIJobTemplate, and an error, if there is any.
+func (c *mPIJobTemplates) Update(ctx context.Context, mPIJobTemplate *v2beta1.MPIJobTemplate, opts v1.UpdateOptions) (result *v2beta1.MPIJobTemplate, err error) {
+	result = &v2beta1.MPIJobTemplate{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		Name(mPIJobTemplate.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(mPIJobTemplate).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the mPIJobTemplate and deletes it. Returns an error if one occurs.
+func (c *mPIJobTemplates) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *mPIJobTemplates) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched mPIJobTemplate.
+func (c *mPIJobTemplates) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v2beta1.MPIJobTemplate, err error) {
+	result = &v2beta1.MPIJobTemplate{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("mpijobtemplates").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
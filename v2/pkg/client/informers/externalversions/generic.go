@@ -51,8 +51,14 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=kubeflow.org, Version=v2beta1
+	case v2beta1.SchemeGroupVersion.WithResource("cronmpijobs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V2beta1().CronMPIJobs().Informer()}, nil
 	case v2beta1.SchemeGroupVersion.WithResource("mpijobs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V2beta1().MPIJobs().Informer()}, nil
+	case v2beta1.SchemeGroupVersion.WithResource("mpijobarrays"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V2beta1().MPIJobArrays().Informer()}, nil
+	case v2beta1.SchemeGroupVersion.WithResource("mpijobtemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kubeflow().V2beta1().MPIJobTemplates().Informer()}, nil
 
 	}
 
@@ -22,8 +22,14 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// CronMPIJobs returns a CronMPIJobInformer.
+	CronMPIJobs() CronMPIJobInformer
 	// MPIJobs returns a MPIJobInformer.
 	MPIJobs() MPIJobInformer
+	// MPIJobArrays returns a MPIJobArrayInformer.
+	MPIJobArrays() MPIJobArrayInformer
+	// MPIJobTemplates returns a MPIJobTemplateInformer.
+	MPIJobTemplates() MPIJobTemplateInformer
 }
 
 type version struct {
@@ -37,7 +43,22 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// CronMPIJobs returns a CronMPIJobInformer.
+func (v *version) CronMPIJobs() CronMPIJobInformer {
+	return &cronMPIJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // MPIJobs returns a MPIJobInformer.
 func (v *version) MPIJobs() MPIJobInformer {
 	return &mPIJobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// MPIJobArrays returns a MPIJobArrayInformer.
+func (v *version) MPIJobArrays() MPIJobArrayInformer {
+	return &mPIJobArrayInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// MPIJobTemplates returns a MPIJobTemplateInformer.
+func (v *version) MPIJobTemplates() MPIJobTemplateInformer {
+	return &mPIJobTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
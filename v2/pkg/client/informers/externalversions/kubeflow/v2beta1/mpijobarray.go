@@ -0,0 +1,88 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v2beta1
+
+import (
+	"context"
+	time "time"
+
+	kubeflowv2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	versioned "github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/kubeflow/mpi-operator/v2/pkg/client/informers/externalversions/internalinterfaces"
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/client/listers/kubeflow/v2beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// MPIJobArrayInformer provides access to a shared informer and lister for
+// MPIJobArrays.
+type MPIJobArrayInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v2beta1.MPIJobArrayLister
+}
+
+type mPIJobArrayInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewMPIJobArrayInformer constructs a new informer for MPIJobArray type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewMPIJobArrayInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredMPIJobArrayInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredMPIJobArrayInformer constructs a new informer for MPIJobArray type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredMPIJobArrayInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.KubeflowV2beta1().MPIJobArrays(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.KubeflowV2beta1().MPIJobArrays(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&kubeflowv2beta1.MPIJobArray{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *mPIJobArrayInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredMPIJobArrayInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *mPIJobArrayInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&kubeflowv2beta1.MPIJobArray{}, f.defaultInformer)
+}
+
+func (f *mPIJobArrayInformer) Lister() v2beta1.MPIJobArrayLister {
+	return v2beta1.NewMPIJobArrayLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2beta1
+
+import (
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CronMPIJobLister helps list CronMPIJobs.
+// All objects returned here must be treated as read-only.
+type CronMPIJobLister interface {
+	// List lists all CronMPIJobs in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v2beta1.CronMPIJob, err error)
+	// CronMPIJobs returns an object that can list and get CronMPIJobs.
+	CronMPIJobs(namespace string) CronMPIJobNamespaceLister
+	CronMPIJobListerExpansion
+}
+
+// cronMPIJobLister implements the CronMPIJobLister interface.
+type cronMPIJobLister struct {
+	indexer cache.Indexer
+}
+
+// NewCronMPIJobLister returns a new CronMPIJobLister.
+func NewCronMPIJobLister(indexer cache.Indexer) CronMPIJobLister {
+	return &cronMPIJobLister{indexer: indexer}
+}
+
+// List lists all CronMPIJobs in the indexer.
+func (s *cronMPIJobLister) List(selector labels.Selector) (ret []*v2beta1.CronMPIJob, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2beta1.CronMPIJob))
+	})
+	return ret, err
+}
+
+// CronMPIJobs returns an object that can list and get CronMPIJobs.
+func (s *cronMPIJobLister) CronMPIJobs(namespace string) CronMPIJobNamespaceLister {
+	return cronMPIJobNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// CronMPIJobNamespaceLister helps list and get CronMPIJobs.
+// All objects returned here must be treated as read-only.
+type CronMPIJobNamespaceLister interface {
+	// List lists all CronMPIJobs in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v2beta1.CronMPIJob, err error)
+	// Get retrieves the CronMPIJob from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v2beta1.CronMPIJob, error)
+	CronMPIJobNamespaceListerExpansion
+}
+
+// cronMPIJobNamespaceLister implements the CronMPIJobNamespaceLister
+// interface.
+type cronMPIJobNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all CronMPIJobs in the indexer for a given namespace.
+func (s cronMPIJobNamespaceLister) List(selector labels.Selector) (ret []*v2beta1.CronMPIJob, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2beta1.CronMPIJob))
+	})
+	return ret, err
+}
+
+// Get retrieves the CronMPIJob from the indexer for a given namespace and name.
+func (s cronMPIJobNamespaceLister) Get(name string) (*v2beta1.CronMPIJob, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v2beta1.Resource("cronmpijob"), name)
+	}
+	return obj.(*v2beta1.CronMPIJob), nil
+}
@@ -16,6 +16,14 @@
 
 package v2beta1
 
+// CronMPIJobListerExpansion allows custom methods to be added to
+// CronMPIJobLister.
+type CronMPIJobListerExpansion interface{}
+
+// CronMPIJobNamespaceListerExpansion allows custom methods to be added to
+// CronMPIJobNamespaceLister.
+type CronMPIJobNamespaceListerExpansion interface{}
+
 // MPIJobListerExpansion allows custom methods to be added to
 // MPIJobLister.
 type MPIJobListerExpansion interface{}
@@ -23,3 +31,19 @@ type MPIJobListerExpansion interface{}
 // MPIJobNamespaceListerExpansion allows custom methods to be added to
 // MPIJobNamespaceLister.
 type MPIJobNamespaceListerExpansion interface{}
+
+// MPIJobArrayListerExpansion allows custom methods to be added to
+// MPIJobArrayLister.
+type MPIJobArrayListerExpansion interface{}
+
+// MPIJobArrayNamespaceListerExpansion allows custom methods to be added to
+// MPIJobArrayNamespaceLister.
+type MPIJobArrayNamespaceListerExpansion interface{}
+
+// MPIJobTemplateListerExpansion allows custom methods to be added to
+// MPIJobTemplateLister.
+type MPIJobTemplateListerExpansion interface{}
+
+// MPIJobTemplateNamespaceListerExpansion allows custom methods to be added to
+// MPIJobTemplateNamespaceLister.
+type MPIJobTemplateNamespaceListerExpansion interface{}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2beta1
+
+import (
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// MPIJobArrayLister helps list MPIJobArrays.
+// All objects returned here must be treated as read-only.
+type MPIJobArrayLister interface {
+	// List lists all MPIJobArrays in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v2beta1.MPIJobArray, err error)
+	// MPIJobArrays returns an object that can list and get MPIJobArrays.
+	MPIJobArrays(namespace string) MPIJobArrayNamespaceLister
+	MPIJobArrayListerExpansion
+}
+
+// mPIJobArrayLister implements the MPIJobArrayLister interface.
+type mPIJobArrayLister struct {
+	indexer cache.Indexer
+}
+
+// NewMPIJobArrayLister returns a new MPIJobArrayLister.
+func NewMPIJobArrayLister(indexer cache.Indexer) MPIJobArrayLister {
+	return &mPIJobArrayLister{indexer: indexer}
+}
+
+// List lists all MPIJobArrays in the indexer.
+func (s *mPIJobArrayLister) List(selector labels.Selector) (ret []*v2beta1.MPIJobArray, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2beta1.MPIJobArray))
+	})
+	return ret, err
+}
+
+// MPIJobArrays returns an object that can list and get MPIJobArrays.
+func (s *mPIJobArrayLister) MPIJobArrays(namespace string) MPIJobArrayNamespaceLister {
+	return mPIJobArrayNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// MPIJobArrayNamespaceLister helps list and get MPIJobArrays.
+// All objects returned here must be treated as read-only.
+type MPIJobArrayNamespaceLister interface {
+	// List lists all MPIJobArrays in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v2beta1.MPIJobArray, err error)
+	// Get retrieves the MPIJobArray from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v2beta1.MPIJobArray, error)
+	MPIJobArrayNamespaceListerExpansion
+}
+
+// mPIJobArrayNamespaceLister implements the MPIJobArrayNamespaceLister
+// interface.
+type mPIJobArrayNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all MPIJobArrays in the indexer for a given namespace.
+func (s mPIJobArrayNamespaceLister) List(selector labels.Selector) (ret []*v2beta1.MPIJobArray, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2beta1.MPIJobArray))
+	})
+	return ret, err
+}
+
+// Get retrieves the MPIJobArray from the indexer for a given namespace and name.
+func (s mPIJobArrayNamespaceLister) Get(name string) (*v2beta1.MPIJobArray, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v2beta1.Resource("mpijobarray"), name)
+	}
+	return obj.(*v2beta1.MPIJobArray), nil
+}
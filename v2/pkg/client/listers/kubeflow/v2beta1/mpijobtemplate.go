@@ -0,0 +1,97 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2beta1
+
+import (
+	v2beta1 "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// MPIJobTemplateLister helps list MPIJobTemplates.
+// All objects returned here must be treated as read-only.
+type MPIJobTemplateLister interface {
+	// List lists all MPIJobTemplates in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v2beta1.MPIJobTemplate, err error)
+	// MPIJobTemplates returns an object that can list and get MPIJobTemplates.
+	MPIJobTemplates(namespace string) MPIJobTemplateNamespaceLister
+	MPIJobTemplateListerExpansion
+}
+
+// mPIJobTemplateLister implements the MPIJobTemplateLister interface.
+type mPIJobTemplateLister struct {
+	indexer cache.Indexer
+}
+
+// NewMPIJobTemplateLister returns a new MPIJobTemplateLister.
+func NewMPIJobTemplateLister(indexer cache.Indexer) MPIJobTemplateLister {
+	return &mPIJobTemplateLister{indexer: indexer}
+}
+
+// List lists all MPIJobTemplates in the indexer.
+func (s *mPIJobTemplateLister) List(selector labels.Selector) (ret []*v2beta1.MPIJobTemplate, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2beta1.MPIJobTemplate))
+	})
+	return ret, err
+}
+
+// MPIJobTemplates returns an object that can list and get MPIJobTemplates.
+func (s *mPIJobTemplateLister) MPIJobTemplates(namespace string) MPIJobTemplateNamespaceLister {
+	return mPIJobTemplateNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// MPIJobTemplateNamespaceLister helps list and get MPIJobTemplates.
+// All objects returned here must be treated as read-only.
+type MPIJobTemplateNamespaceLister interface {
+	// List lists all MPIJobTemplates in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v2beta1.MPIJobTemplate, err error)
+	// Get retrieves the MPIJobTemplate from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v2beta1.MPIJobTemplate, error)
+	MPIJobTemplateNamespaceListerExpansion
+}
+
+// mPIJobTemplateNamespaceLister implements the MPIJobTemplateNamespaceLister
+// interface.
+type mPIJobTemplateNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all MPIJobTemplates in the indexer for a given namespace.
+func (s mPIJobTemplateNamespaceLister) List(selector labels.Selector) (ret []*v2beta1.MPIJobTemplate, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v2beta1.MPIJobTemplate))
+	})
+	return ret, err
+}
+
+// Get retrieves the MPIJobTemplate from the indexer for a given namespace and name.
+func (s mPIJobTemplateNamespaceLister) Get(name string) (*v2beta1.MPIJobTemplate, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v2beta1.Resource("mpijobtemplate"), name)
+	}
+	return obj.(*v2beta1.MPIJobTemplate), nil
+}
@@ -0,0 +1,424 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/validation"
+	clientset "github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned"
+	"github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned/scheme"
+	informers "github.com/kubeflow/mpi-operator/v2/pkg/client/informers/externalversions/kubeflow/v2beta1"
+	listers "github.com/kubeflow/mpi-operator/v2/pkg/client/listers/kubeflow/v2beta1"
+)
+
+const (
+	cronControllerAgentName = "cron-mpi-job-controller"
+
+	// maxMissedSchedules is the number of missed runs after which the
+	// controller stops trying to catch up with a CronMPIJob.
+	maxMissedSchedules = 100
+
+	// nextScheduleDelta is added to the time until the next run when
+	// requeueing a CronMPIJob, so that the sync happens after the scheduled
+	// time rather than right before.
+	nextScheduleDelta = 100 * time.Millisecond
+
+	cronJobCreatedReason   = "SuccessfulCreate"
+	cronJobDeletedReason   = "SuccessfulDelete"
+	cronJobMissedReason    = "MissSchedule"
+	cronJobForbiddenReason = "JobAlreadyActive"
+)
+
+// CronMPIJobController creates MPIJobs from CronMPIJobs on their schedule.
+type CronMPIJobController struct {
+	kubeflowClient clientset.Interface
+
+	cronMPIJobLister listers.CronMPIJobLister
+	cronMPIJobSynced cache.InformerSynced
+	mpiJobLister     listers.MPIJobLister
+	mpiJobSynced     cache.InformerSynced
+
+	// queue holds the keys of the CronMPIJobs to sync. They are enqueued
+	// when they or their MPIJobs change, and at their next scheduled time.
+	queue workqueue.RateLimitingInterface
+
+	recorder record.EventRecorder
+
+	// To allow injection of the current time for testing.
+	now func() time.Time
+}
+
+// NewCronMPIJobController returns a new CronMPIJob controller.
+func NewCronMPIJobController(
+	kubeClient kubernetes.Interface,
+	kubeflowClient clientset.Interface,
+	cronMPIJobInformer informers.CronMPIJobInformer,
+	mpiJobInformer informers.MPIJobInformer,
+	eventOptions record.CorrelatorOptions) *CronMPIJobController {
+
+	eventBroadcaster := record.NewBroadcasterWithCorrelatorOptions(eventOptions)
+	eventBroadcaster.StartLogging(klog.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: cronControllerAgentName})
+
+	controller := &CronMPIJobController{
+		kubeflowClient:   kubeflowClient,
+		cronMPIJobLister: cronMPIJobInformer.Lister(),
+		cronMPIJobSynced: cronMPIJobInformer.Informer().HasSynced,
+		mpiJobLister:     mpiJobInformer.Lister(),
+		mpiJobSynced:     mpiJobInformer.Informer().HasSynced,
+		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "CronMPIJobs"),
+		recorder:         recorder,
+		now:              time.Now,
+	}
+
+	cronMPIJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueCronMPIJob,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueCronMPIJob(new)
+		},
+	})
+	mpiJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleMPIJob,
+		UpdateFunc: func(old, new interface{}) {
+			controller.handleMPIJob(new)
+		},
+		DeleteFunc: controller.handleMPIJob,
+	})
+	return controller
+}
+
+// Run starts the workers that sync the CronMPIJobs and blocks until stopCh
+// is closed.
+func (c *CronMPIJobController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting CronMPIJob controller")
+	if ok := cache.WaitForCacheSync(stopCh, c.cronMPIJobSynced, c.mpiJobSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(func() {
+			for processNextKey(c.queue, c.syncHandler) {
+			}
+		}, time.Second, stopCh)
+	}
+	<-stopCh
+	klog.Info("Shutting down CronMPIJob controller")
+	return nil
+}
+
+func (c *CronMPIJobController) enqueueCronMPIJob(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// handleMPIJob enqueues the CronMPIJob that controls the MPIJob, if any.
+func (c *CronMPIJobController) handleMPIJob(obj interface{}) {
+	if key, ok := controllerKey(obj, "CronMPIJob"); ok {
+		c.queue.Add(key)
+	}
+}
+
+// syncHandler syncs the CronMPIJob with the given key and requeues it for
+// its next scheduled time.
+func (c *CronMPIJobController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	cronJob, err := c.cronMPIJobLister.CronMPIJobs(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("obtaining CronMPIJob: %w", err)
+	}
+	requeueAfter, err := c.syncCronMPIJob(cronJob)
+	if err != nil {
+		return err
+	}
+	if requeueAfter != nil {
+		klog.V(4).Infof("Requeueing CronMPIJob %s after %v", key, *requeueAfter)
+		c.queue.AddAfter(key, *requeueAfter)
+	}
+	return nil
+}
+
+// syncCronMPIJob reconciles the active and finished MPIJobs of a CronMPIJob
+// and starts a new MPIJob if a run is due. It returns the time until the
+// next run, or nil if the CronMPIJob doesn't have to be synced until it
+// changes.
+func (c *CronMPIJobController) syncCronMPIJob(sharedCronJob *kubeflow.CronMPIJob) (*time.Duration, error) {
+	if sharedCronJob.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	cronJob := sharedCronJob.DeepCopy()
+	scheme.Scheme.Default(cronJob)
+
+	if errs := validation.ValidateCronMPIJob(cronJob); len(errs) != 0 {
+		msg := truncateMessage(fmt.Sprintf("Found validation errors: %v", errs.ToAggregate()))
+		c.recorder.Event(cronJob, corev1.EventTypeWarning, ValidationError, msg)
+		return nil, nil
+	}
+
+	jobs, err := c.ownedMPIJobs(cronJob)
+	if err != nil {
+		return nil, err
+	}
+	var active, succeeded, failed []*kubeflow.MPIJob
+	for _, j := range jobs {
+		switch {
+		case isSucceeded(j.Status):
+			succeeded = append(succeeded, j)
+		case isFailed(j.Status):
+			failed = append(failed, j)
+		default:
+			active = append(active, j)
+		}
+	}
+	cronJob.Status.Active = objectReferences(active)
+
+	c.cleanupFinishedJobs(cronJob, succeeded, *cronJob.Spec.SuccessfulJobsHistoryLimit)
+	c.cleanupFinishedJobs(cronJob, failed, *cronJob.Spec.FailedJobsHistoryLimit)
+
+	var requeueAfter *time.Duration
+	if !*cronJob.Spec.Suspend {
+		if err := c.runIfScheduled(cronJob, active); err != nil {
+			return nil, err
+		}
+		requeueAfter = nextScheduleTimeDuration(cronJob, c.now())
+	}
+
+	if !reflect.DeepEqual(sharedCronJob.Status, cronJob.Status) {
+		if _, err := c.kubeflowClient.KubeflowV2beta1().CronMPIJobs(cronJob.Namespace).UpdateStatus(context.TODO(), cronJob, metav1.UpdateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	return requeueAfter, nil
+}
+
+// runIfScheduled creates an MPIJob for the most recent unmet schedule time,
+// honoring the concurrency policy and the starting deadline.
+func (c *CronMPIJobController) runIfScheduled(cronJob *kubeflow.CronMPIJob, active []*kubeflow.MPIJob) error {
+	now := c.now()
+	scheduledTime, err := mostRecentScheduleTime(cronJob, now)
+	if err != nil {
+		c.recorder.Event(cronJob, corev1.EventTypeWarning, cronJobMissedReason, err.Error())
+		return nil
+	}
+	if scheduledTime == nil {
+		return nil
+	}
+	if d := cronJob.Spec.StartingDeadlineSeconds; d != nil && scheduledTime.Add(time.Duration(*d)*time.Second).Before(now) {
+		c.recorder.Eventf(cronJob, corev1.EventTypeWarning, cronJobMissedReason, "Missed scheduled time to start a job: %s", scheduledTime.Format(time.RFC1123Z))
+		return nil
+	}
+
+	name := fmt.Sprintf("%s-%d", cronJob.Name, scheduledTime.Unix()/60)
+	if _, err := c.mpiJobLister.MPIJobs(cronJob.Namespace).Get(name); err == nil {
+		// The run was already started, but the status wasn't updated.
+		cronJob.Status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+		return nil
+	}
+
+	switch cronJob.Spec.ConcurrencyPolicy {
+	case kubeflow.ForbidConcurrent:
+		if len(active) > 0 {
+			klog.V(4).Infof("Not starting MPIJob for CronMPIJob %s/%s because of %d active runs", cronJob.Namespace, cronJob.Name, len(active))
+			c.recorder.Eventf(cronJob, corev1.EventTypeNormal, cronJobForbiddenReason, "Not starting job because prior execution is still running and concurrency policy is Forbid")
+			return nil
+		}
+	case kubeflow.ReplaceConcurrent:
+		for _, j := range active {
+			if err := c.deleteMPIJob(cronJob, j); err != nil {
+				return err
+			}
+		}
+		cronJob.Status.Active = nil
+	}
+
+	job := newMPIJobFromCron(cronJob, name)
+	if errs := validateNewMPIJob(job); len(errs) != 0 {
+		msg := truncateMessage(fmt.Sprintf("Found validation errors in jobTemplate: %v", errs.ToAggregate()))
+		c.recorder.Event(cronJob, corev1.EventTypeWarning, ValidationError, msg)
+		return nil
+	}
+	job, err = c.kubeflowClient.KubeflowV2beta1().MPIJobs(cronJob.Namespace).Create(context.TODO(), job, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		c.recorder.Eventf(cronJob, corev1.EventTypeWarning, "FailedCreate", "Error creating job: %v", err)
+		return fmt.Errorf("creating MPIJob: %w", err)
+	}
+	if err == nil {
+		c.recorder.Eventf(cronJob, corev1.EventTypeNormal, cronJobCreatedReason, "Created job %s", job.Name)
+		cronJob.Status.Active = append(cronJob.Status.Active, objectReferences([]*kubeflow.MPIJob{job})...)
+	}
+	cronJob.Status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+	return nil
+}
+
+// ownedMPIJobs returns the MPIJobs controlled by the CronMPIJob.
+func (c *CronMPIJobController) ownedMPIJobs(cronJob *kubeflow.CronMPIJob) ([]*kubeflow.MPIJob, error) {
+	owned, err := controlledMPIJobs(c.mpiJobLister, cronJob)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].CreationTimestamp.Before(&owned[j].CreationTimestamp) ||
+			(owned[i].CreationTimestamp.Equal(&owned[j].CreationTimestamp) && owned[i].Name < owned[j].Name)
+	})
+	return owned, nil
+}
+
+// controlledMPIJobs returns the MPIJobs in the namespace of owner that are
+// controlled by it.
+func controlledMPIJobs(lister listers.MPIJobLister, owner metav1.Object) ([]*kubeflow.MPIJob, error) {
+	jobs, err := lister.MPIJobs(owner.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("listing MPIJobs: %w", err)
+	}
+	var owned []*kubeflow.MPIJob
+	for _, j := range jobs {
+		if metav1.IsControlledBy(j, owner) {
+			owned = append(owned, j)
+		}
+	}
+	return owned, nil
+}
+
+// cleanupFinishedJobs deletes the oldest finished MPIJobs beyond the limit.
+// The jobs must be sorted by creation time.
+func (c *CronMPIJobController) cleanupFinishedJobs(cronJob *kubeflow.CronMPIJob, jobs []*kubeflow.MPIJob, limit int32) {
+	for i := 0; i < len(jobs)-int(limit); i++ {
+		if err := c.deleteMPIJob(cronJob, jobs[i]); err != nil {
+			runtime.HandleError(err)
+		}
+	}
+}
+
+func (c *CronMPIJobController) deleteMPIJob(cronJob *kubeflow.CronMPIJob, job *kubeflow.MPIJob) error {
+	policy := metav1.DeletePropagationBackground
+	err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(job.Namespace).Delete(context.TODO(), job.Name, metav1.DeleteOptions{PropagationPolicy: &policy})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting MPIJob %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	c.recorder.Eventf(cronJob, corev1.EventTypeNormal, cronJobDeletedReason, "Deleted job %s", job.Name)
+	return nil
+}
+
+// mostRecentScheduleTime returns the latest schedule time between the last
+// run, or the creation of the CronMPIJob, and now. It returns nil if there
+// is no unmet schedule time.
+func mostRecentScheduleTime(cronJob *kubeflow.CronMPIJob, now time.Time) (*time.Time, error) {
+	sched, err := cron.ParseStandard(cronJob.Spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("unparseable schedule %q: %w", cronJob.Spec.Schedule, err)
+	}
+	earliest := cronJob.CreationTimestamp.Time
+	if cronJob.Status.LastScheduleTime != nil {
+		earliest = cronJob.Status.LastScheduleTime.Time
+	}
+	if d := cronJob.Spec.StartingDeadlineSeconds; d != nil {
+		// Runs older than the deadline can't start anyway.
+		if deadline := now.Add(-time.Duration(*d) * time.Second); deadline.After(earliest) {
+			earliest = deadline
+		}
+	}
+	var (
+		last   *time.Time
+		missed int
+	)
+	for t := sched.Next(earliest); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		t := t
+		last = &t
+		missed++
+		if missed > maxMissedSchedules {
+			return nil, fmt.Errorf("too many missed start times (> %d); set or decrease .spec.startingDeadlineSeconds or check clock skew", maxMissedSchedules)
+		}
+	}
+	return last, nil
+}
+
+// nextScheduleTimeDuration returns the time from now until shortly after the
+// next scheduled run of the CronMPIJob, or nil if it never runs again.
+func nextScheduleTimeDuration(cronJob *kubeflow.CronMPIJob, now time.Time) *time.Duration {
+	sched, err := cron.ParseStandard(cronJob.Spec.Schedule)
+	if err != nil {
+		return nil
+	}
+	next := sched.Next(now)
+	if next.IsZero() {
+		return nil
+	}
+	d := next.Sub(now) + nextScheduleDelta
+	return &d
+}
+
+// newMPIJobFromCron creates a new MPIJob from the template of a CronMPIJob.
+func newMPIJobFromCron(cronJob *kubeflow.CronMPIJob, name string) *kubeflow.MPIJob {
+	template := cronJob.Spec.JobTemplate.DeepCopy()
+	return &kubeflow.MPIJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   cronJob.Namespace,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronJob, kubeflow.SchemeGroupVersion.WithKind("CronMPIJob")),
+			},
+		},
+		Spec: template.Spec,
+	}
+}
+
+func objectReferences(jobs []*kubeflow.MPIJob) []corev1.ObjectReference {
+	var refs []corev1.ObjectReference
+	for _, j := range jobs {
+		refs = append(refs, corev1.ObjectReference{
+			APIVersion: kubeflow.SchemeGroupVersion.String(),
+			Kind:       kubeflow.Kind,
+			Namespace:  j.Namespace,
+			Name:       j.Name,
+			UID:        j.UID,
+		})
+	}
+	return refs
+}
@@ -0,0 +1,288 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	common "github.com/kubeflow/common/pkg/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned/fake"
+	informers "github.com/kubeflow/mpi-operator/v2/pkg/client/informers/externalversions"
+)
+
+var cronNow = time.Date(2021, time.March, 3, 10, 15, 30, 0, time.UTC)
+
+func newCronMPIJob(name, schedule string) *kubeflow.CronMPIJob {
+	return &kubeflow.CronMPIJob{
+		TypeMeta: metav1.TypeMeta{APIVersion: kubeflow.SchemeGroupVersion.String(), Kind: "CronMPIJob"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         metav1.NamespaceDefault,
+			UID:               "cron-uid",
+			CreationTimestamp: metav1.NewTime(cronNow.Add(-time.Hour)),
+		},
+		Spec: kubeflow.CronMPIJobSpec{
+			Schedule: schedule,
+			JobTemplate: kubeflow.MPIJobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "benchmark"},
+				},
+				Spec: newMPIJob("tmpl", newInt32(1), nil, nil).Spec,
+			},
+		},
+	}
+}
+
+func newCronController(t *testing.T, cronJob *kubeflow.CronMPIJob, jobs ...*kubeflow.MPIJob) (*CronMPIJobController, *fake.Clientset) {
+	objects := []runtime.Object{cronJob}
+	for _, j := range jobs {
+		objects = append(objects, j)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	i := informers.NewSharedInformerFactory(client, noResyncPeriodFunc())
+	c := &CronMPIJobController{
+		kubeflowClient:   client,
+		cronMPIJobLister: i.Kubeflow().V2beta1().CronMPIJobs().Lister(),
+		mpiJobLister:     i.Kubeflow().V2beta1().MPIJobs().Lister(),
+		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "CronMPIJobs"),
+		recorder:         &record.FakeRecorder{},
+		now:              func() time.Time { return cronNow },
+	}
+	if err := i.Kubeflow().V2beta1().CronMPIJobs().Informer().GetIndexer().Add(cronJob); err != nil {
+		t.Fatalf("Failed adding CronMPIJob to informer: %v", err)
+	}
+	for _, j := range jobs {
+		if err := i.Kubeflow().V2beta1().MPIJobs().Informer().GetIndexer().Add(j); err != nil {
+			t.Fatalf("Failed adding MPIJob to informer: %v", err)
+		}
+	}
+	return c, client
+}
+
+func newCronRun(cronJob *kubeflow.CronMPIJob, name string, created time.Time, condition common.JobConditionType) *kubeflow.MPIJob {
+	job := newMPIJobFromCron(cronJob, name)
+	job.CreationTimestamp = metav1.NewTime(created)
+	if condition != "" {
+		job.Status.Conditions = []common.JobCondition{newCondition(condition, "", "")}
+	}
+	return job
+}
+
+func TestMostRecentScheduleTime(t *testing.T) {
+	cases := map[string]struct {
+		schedule     string
+		lastSchedule *time.Time
+		deadline     *int64
+		want         *time.Time
+		wantErr      bool
+	}{
+		"since creation": {
+			schedule: "0 * * * *",
+			want:     timePtr(time.Date(2021, time.March, 3, 10, 0, 0, 0, time.UTC)),
+		},
+		"already run": {
+			schedule:     "0 * * * *",
+			lastSchedule: timePtr(time.Date(2021, time.March, 3, 10, 0, 0, 0, time.UTC)),
+		},
+		"missed runs": {
+			schedule:     "*/5 * * * *",
+			lastSchedule: timePtr(time.Date(2021, time.March, 3, 9, 0, 0, 0, time.UTC)),
+			want:         timePtr(time.Date(2021, time.March, 3, 10, 15, 0, 0, time.UTC)),
+		},
+		"too many missed": {
+			schedule:     "* * * * *",
+			lastSchedule: timePtr(cronNow.Add(-24 * time.Hour)),
+			wantErr:      true,
+		},
+		"deadline limits missed": {
+			schedule:     "* * * * *",
+			lastSchedule: timePtr(cronNow.Add(-24 * time.Hour)),
+			deadline:     newInt64(600),
+			want:         timePtr(time.Date(2021, time.March, 3, 10, 15, 0, 0, time.UTC)),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cronJob := newCronMPIJob("foo", tc.schedule)
+			cronJob.Spec.StartingDeadlineSeconds = tc.deadline
+			if tc.lastSchedule != nil {
+				cronJob.Status.LastScheduleTime = &metav1.Time{Time: *tc.lastSchedule}
+			}
+			got, err := mostRecentScheduleTime(cronJob, cronNow)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("mostRecentScheduleTime returned error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected schedule time (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCronMPIJobCreatesRun(t *testing.T) {
+	cronJob := newCronMPIJob("foo", "0 * * * *")
+	c, client := newCronController(t, cronJob)
+	if _, err := c.syncCronMPIJob(cronJob); err != nil {
+		t.Fatalf("Failed syncing CronMPIJob: %v", err)
+	}
+
+	actions := client.Actions()
+	if len(actions) != 2 {
+		t.Fatalf("Got %d actions, want 2: %v", len(actions), actions)
+	}
+	created := actions[0].(core.CreateAction).GetObject().(*kubeflow.MPIJob)
+	wantName := "foo-26912760"
+	if created.Name != wantName {
+		t.Errorf("Created MPIJob %s, want %s", created.Name, wantName)
+	}
+	if created.Labels["app"] != "benchmark" {
+		t.Errorf("Created MPIJob doesn't have the template labels: %v", created.Labels)
+	}
+	if !metav1.IsControlledBy(created, cronJob) {
+		t.Errorf("Created MPIJob is not controlled by the CronMPIJob")
+	}
+	updated := actions[1].(core.UpdateAction).GetObject().(*kubeflow.CronMPIJob)
+	wantTime := time.Date(2021, time.March, 3, 10, 0, 0, 0, time.UTC)
+	if updated.Status.LastScheduleTime == nil || !updated.Status.LastScheduleTime.Time.Equal(wantTime) {
+		t.Errorf("Got last schedule time %v, want %v", updated.Status.LastScheduleTime, wantTime)
+	}
+	if len(updated.Status.Active) != 1 || updated.Status.Active[0].Name != wantName {
+		t.Errorf("Unexpected active runs %v", updated.Status.Active)
+	}
+}
+
+func TestCronMPIJobForbidConcurrent(t *testing.T) {
+	cronJob := newCronMPIJob("foo", "0 * * * *")
+	cronJob.Spec.ConcurrencyPolicy = kubeflow.ForbidConcurrent
+	running := newCronRun(cronJob, "foo-26924460", cronNow.Add(-75*time.Minute), "")
+	c, client := newCronController(t, cronJob, running)
+	if _, err := c.syncCronMPIJob(cronJob); err != nil {
+		t.Fatalf("Failed syncing CronMPIJob: %v", err)
+	}
+	for _, a := range client.Actions() {
+		if a.GetVerb() == "create" {
+			t.Errorf("Unexpected action %v", a)
+		}
+	}
+}
+
+func TestCronMPIJobReplaceConcurrent(t *testing.T) {
+	cronJob := newCronMPIJob("foo", "0 * * * *")
+	cronJob.Spec.ConcurrencyPolicy = kubeflow.ReplaceConcurrent
+	running := newCronRun(cronJob, "foo-26924460", cronNow.Add(-75*time.Minute), "")
+	c, client := newCronController(t, cronJob, running)
+	if _, err := c.syncCronMPIJob(cronJob); err != nil {
+		t.Fatalf("Failed syncing CronMPIJob: %v", err)
+	}
+	var verbs []string
+	for _, a := range client.Actions() {
+		verbs = append(verbs, a.GetVerb()+" "+a.GetResource().Resource)
+	}
+	want := []string{"delete mpijobs", "create mpijobs", "update cronmpijobs"}
+	if diff := cmp.Diff(want, verbs); diff != "" {
+		t.Errorf("Unexpected actions (-want,+got):\n%s", diff)
+	}
+}
+
+func TestCronMPIJobHistoryLimits(t *testing.T) {
+	cronJob := newCronMPIJob("foo", "0 * * * *")
+	cronJob.Spec.SuccessfulJobsHistoryLimit = newInt32(1)
+	cronJob.Spec.FailedJobsHistoryLimit = newInt32(0)
+	cronJob.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2021, time.March, 3, 10, 0, 0, 0, time.UTC)}
+	jobs := []*kubeflow.MPIJob{
+		newCronRun(cronJob, "foo-1", cronNow.Add(-3*time.Hour), common.JobSucceeded),
+		newCronRun(cronJob, "foo-2", cronNow.Add(-2*time.Hour), common.JobSucceeded),
+		newCronRun(cronJob, "foo-3", cronNow.Add(-time.Hour), common.JobFailed),
+	}
+	c, client := newCronController(t, cronJob, jobs...)
+	if _, err := c.syncCronMPIJob(cronJob); err != nil {
+		t.Fatalf("Failed syncing CronMPIJob: %v", err)
+	}
+	var deleted []string
+	for _, a := range client.Actions() {
+		if a.GetVerb() == "delete" {
+			deleted = append(deleted, a.(core.DeleteAction).GetName())
+		}
+	}
+	if diff := cmp.Diff([]string{"foo-1", "foo-3"}, deleted); diff != "" {
+		t.Errorf("Unexpected deleted MPIJobs (-want,+got):\n%s", diff)
+	}
+}
+
+func TestCronMPIJobRequeueAfter(t *testing.T) {
+	cases := map[string]struct {
+		schedule string
+		suspend  bool
+		want     *time.Duration
+	}{
+		"hourly": {
+			schedule: "0 * * * *",
+			want:     durationPtr(44*time.Minute + 30*time.Second + nextScheduleDelta),
+		},
+		"every five minutes": {
+			schedule: "*/5 * * * *",
+			want:     durationPtr(4*time.Minute + 30*time.Second + nextScheduleDelta),
+		},
+		"suspended": {
+			schedule: "0 * * * *",
+			suspend:  true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cronJob := newCronMPIJob("foo", tc.schedule)
+			cronJob.Spec.Suspend = &tc.suspend
+			c, _ := newCronController(t, cronJob)
+			got, err := c.syncCronMPIJob(cronJob)
+			if err != nil {
+				t.Fatalf("Failed syncing CronMPIJob: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected requeue delay (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCronMPIJobHandleMPIJob(t *testing.T) {
+	cronJob := newCronMPIJob("foo", "0 * * * *")
+	c, _ := newCronController(t, cronJob)
+	c.handleMPIJob(newCronRun(cronJob, "foo-1", cronNow, ""))
+	if item, _ := c.queue.Get(); item != "default/foo" {
+		t.Errorf("Enqueued %v, want default/foo", item)
+	}
+	c.queue.Done("default/foo")
+	c.handleMPIJob(newMPIJob("other", newInt32(1), nil, nil))
+	if c.queue.Len() != 0 {
+		t.Errorf("Enqueued %d keys for an MPIJob without controller, want none", c.queue.Len())
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,352 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/validation"
+	clientset "github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned"
+	"github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned/scheme"
+	informers "github.com/kubeflow/mpi-operator/v2/pkg/client/informers/externalversions/kubeflow/v2beta1"
+	listers "github.com/kubeflow/mpi-operator/v2/pkg/client/listers/kubeflow/v2beta1"
+)
+
+const (
+	arrayControllerAgentName = "mpi-job-array-controller"
+
+	// arrayIndexLabel and arrayIndexEnv hold the index of an MPIJob in its
+	// MPIJobArray.
+	arrayIndexLabel = "mpi.kubeflow.org/array-index"
+	arrayIndexEnv   = "MPI_JOB_ARRAY_INDEX"
+
+	arrayJobCreatedReason = "SuccessfulCreate"
+	arrayCompletedReason  = "Completed"
+)
+
+// MPIJobArrayController creates the MPIJobs of MPIJobArrays and aggregates
+// their status.
+type MPIJobArrayController struct {
+	kubeflowClient clientset.Interface
+
+	mpiJobArrayLister listers.MPIJobArrayLister
+	mpiJobArraySynced cache.InformerSynced
+	mpiJobLister      listers.MPIJobLister
+	mpiJobSynced      cache.InformerSynced
+
+	// queue holds the keys of the MPIJobArrays to sync, enqueued when they
+	// or their MPIJobs change.
+	queue workqueue.RateLimitingInterface
+
+	recorder record.EventRecorder
+}
+
+// NewMPIJobArrayController returns a new MPIJobArray controller.
+func NewMPIJobArrayController(
+	kubeClient kubernetes.Interface,
+	kubeflowClient clientset.Interface,
+	mpiJobArrayInformer informers.MPIJobArrayInformer,
+	mpiJobInformer informers.MPIJobInformer,
+	eventOptions record.CorrelatorOptions) *MPIJobArrayController {
+
+	eventBroadcaster := record.NewBroadcasterWithCorrelatorOptions(eventOptions)
+	eventBroadcaster.StartLogging(klog.Infof)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: arrayControllerAgentName})
+
+	controller := &MPIJobArrayController{
+		kubeflowClient:    kubeflowClient,
+		mpiJobArrayLister: mpiJobArrayInformer.Lister(),
+		mpiJobArraySynced: mpiJobArrayInformer.Informer().HasSynced,
+		mpiJobLister:      mpiJobInformer.Lister(),
+		mpiJobSynced:      mpiJobInformer.Informer().HasSynced,
+		queue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "MPIJobArrays"),
+		recorder:          recorder,
+	}
+
+	mpiJobArrayInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueMPIJobArray,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueMPIJobArray(new)
+		},
+	})
+	mpiJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleMPIJob,
+		UpdateFunc: func(old, new interface{}) {
+			controller.handleMPIJob(new)
+		},
+		DeleteFunc: controller.handleMPIJob,
+	})
+	return controller
+}
+
+// Run starts the workers that sync the MPIJobArrays and blocks until stopCh
+// is closed.
+func (c *MPIJobArrayController) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting MPIJobArray controller")
+	if ok := cache.WaitForCacheSync(stopCh, c.mpiJobArraySynced, c.mpiJobSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(func() {
+			for processNextKey(c.queue, c.syncHandler) {
+			}
+		}, time.Second, stopCh)
+	}
+	<-stopCh
+	klog.Info("Shutting down MPIJobArray controller")
+	return nil
+}
+
+// processNextKey reads a key from the queue and syncs it. A failed key is
+// requeued with a backoff. It returns false when the queue is shut down.
+func processNextKey(queue workqueue.RateLimitingInterface, syncHandler func(string) error) bool {
+	obj, shutdown := queue.Get()
+	if shutdown {
+		return false
+	}
+	defer queue.Done(obj)
+	key, ok := obj.(string)
+	if !ok {
+		queue.Forget(obj)
+		runtime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+		return true
+	}
+	if err := syncHandler(key); err != nil {
+		queue.AddRateLimited(key)
+		runtime.HandleError(fmt.Errorf("error syncing '%s': %w", key, err))
+		return true
+	}
+	queue.Forget(obj)
+	return true
+}
+
+func (c *MPIJobArrayController) enqueueMPIJobArray(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// handleMPIJob enqueues the MPIJobArray that controls the MPIJob, if any.
+func (c *MPIJobArrayController) handleMPIJob(obj interface{}) {
+	if key, ok := controllerKey(obj, "MPIJobArray"); ok {
+		c.queue.Add(key)
+	}
+}
+
+// controllerKey returns the key of the controller of an MPIJob, or of the
+// tombstone of a deleted MPIJob, if the controller is of the given kind.
+func controllerKey(obj interface{}, kind string) (string, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	job, ok := obj.(*kubeflow.MPIJob)
+	if !ok {
+		return "", false
+	}
+	ref := metav1.GetControllerOf(job)
+	if ref == nil || ref.Kind != kind || ref.APIVersion != kubeflow.SchemeGroupVersion.String() {
+		return "", false
+	}
+	return job.Namespace + "/" + ref.Name, true
+}
+
+// syncHandler syncs the MPIJobArray with the given key.
+func (c *MPIJobArrayController) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+	array, err := c.mpiJobArrayLister.MPIJobArrays(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("obtaining MPIJobArray: %w", err)
+	}
+	return c.syncMPIJobArray(array)
+}
+
+// syncMPIJobArray creates the missing MPIJobs of an MPIJobArray and updates
+// its status with the number of active, succeeded and failed MPIJobs.
+func (c *MPIJobArrayController) syncMPIJobArray(sharedArray *kubeflow.MPIJobArray) error {
+	if sharedArray.DeletionTimestamp != nil || sharedArray.Status.CompletionTime != nil {
+		return nil
+	}
+	array := sharedArray.DeepCopy()
+
+	if errs := validation.ValidateMPIJobArray(array); len(errs) != 0 {
+		msg := truncateMessage(fmt.Sprintf("Found validation errors: %v", errs.ToAggregate()))
+		c.recorder.Event(array, corev1.EventTypeWarning, ValidationError, msg)
+		return nil
+	}
+
+	jobs, err := controlledMPIJobs(c.mpiJobLister, array)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*kubeflow.MPIJob, len(jobs))
+	for _, j := range jobs {
+		existing[j.Name] = j
+	}
+
+	array.Status.Active, array.Status.Succeeded, array.Status.Failed = 0, 0, 0
+	for i := range array.Spec.Parameters {
+		job := existing[mpiJobArrayInstanceName(array, i)]
+		if job == nil {
+			if job, err = c.createInstance(array, i); err != nil {
+				return err
+			}
+			if job == nil {
+				// The instance is invalid and was reported in an event. It
+				// can never run, so it counts as failed.
+				array.Status.Failed++
+				continue
+			}
+		}
+		switch {
+		case isSucceeded(job.Status):
+			array.Status.Succeeded++
+		case isFailed(job.Status):
+			array.Status.Failed++
+		default:
+			array.Status.Active++
+		}
+	}
+	if int(array.Status.Succeeded+array.Status.Failed) == len(array.Spec.Parameters) {
+		now := metav1.Now()
+		array.Status.CompletionTime = &now
+		c.recorder.Eventf(array, corev1.EventTypeNormal, arrayCompletedReason, "MPIJobArray completed: %d succeeded, %d failed", array.Status.Succeeded, array.Status.Failed)
+	}
+
+	if reflect.DeepEqual(sharedArray.Status, array.Status) {
+		return nil
+	}
+	_, err = c.kubeflowClient.KubeflowV2beta1().MPIJobArrays(array.Namespace).UpdateStatus(context.TODO(), array, metav1.UpdateOptions{})
+	return err
+}
+
+// createInstance creates the MPIJob for the parameter set at index. It
+// returns nil if the resulting MPIJob is invalid, and an error if an MPIJob
+// with the same name exists but is not controlled by the MPIJobArray.
+func (c *MPIJobArrayController) createInstance(array *kubeflow.MPIJobArray, index int) (*kubeflow.MPIJob, error) {
+	job := newMPIJobArrayInstance(array, index)
+	if errs := validateNewMPIJob(job); len(errs) != 0 {
+		msg := truncateMessage(fmt.Sprintf("Found validation errors in instance %d: %v", index, errs.ToAggregate()))
+		c.recorder.Event(array, corev1.EventTypeWarning, ValidationError, msg)
+		return nil, nil
+	}
+	created, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(array.Namespace).Create(context.TODO(), job, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		// Either the informer hasn't observed the MPIJob yet, or it belongs
+		// to something else.
+		existing, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(array.Namespace).Get(context.TODO(), job.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("obtaining MPIJob: %w", err)
+		}
+		if !metav1.IsControlledBy(existing, array) {
+			msg := fmt.Sprintf(MessageResourceExists, existing.Name, "MPIJob")
+			c.recorder.Event(array, corev1.EventTypeWarning, ErrResourceExists, msg)
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return existing, nil
+	}
+	if err != nil {
+		c.recorder.Eventf(array, corev1.EventTypeWarning, "FailedCreate", "Error creating job: %v", err)
+		return nil, fmt.Errorf("creating MPIJob: %w", err)
+	}
+	c.recorder.Eventf(array, corev1.EventTypeNormal, arrayJobCreatedReason, "Created job %s", created.Name)
+	return created, nil
+}
+
+func mpiJobArrayInstanceName(array *kubeflow.MPIJobArray, index int) string {
+	return fmt.Sprintf("%s-%d", array.Name, index)
+}
+
+// newMPIJobArrayInstance creates the MPIJob for the parameter set at index,
+// with the parameters and the index set in the environment of all the
+// containers.
+func newMPIJobArrayInstance(array *kubeflow.MPIJobArray, index int) *kubeflow.MPIJob {
+	template := array.Spec.JobTemplate.DeepCopy()
+	jobLabels := template.Labels
+	if jobLabels == nil {
+		jobLabels = make(map[string]string)
+	}
+	jobLabels[arrayIndexLabel] = strconv.Itoa(index)
+
+	env := append([]corev1.EnvVar{{Name: arrayIndexEnv, Value: strconv.Itoa(index)}}, array.Spec.Parameters[index].Env...)
+	for _, rs := range template.Spec.MPIReplicaSpecs {
+		if rs == nil {
+			continue
+		}
+		for i := range rs.Template.Spec.Containers {
+			setEnv(&rs.Template.Spec.Containers[i], env)
+		}
+	}
+	return &kubeflow.MPIJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        mpiJobArrayInstanceName(array, index),
+			Namespace:   array.Namespace,
+			Labels:      jobLabels,
+			Annotations: template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(array, kubeflow.SchemeGroupVersion.WithKind("MPIJobArray")),
+			},
+		},
+		Spec: template.Spec,
+	}
+}
+
+// setEnv sets the environment variables in the container, replacing the ones
+// with the same name.
+func setEnv(container *corev1.Container, env []corev1.EnvVar) {
+	for _, e := range env {
+		replaced := false
+		for i := range container.Env {
+			if container.Env[i].Name == e.Name {
+				container.Env[i] = *e.DeepCopy()
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			container.Env = append(container.Env, *e.DeepCopy())
+		}
+	}
+}
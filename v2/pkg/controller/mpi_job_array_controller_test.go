@@ -0,0 +1,279 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	common "github.com/kubeflow/common/pkg/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned/fake"
+	informers "github.com/kubeflow/mpi-operator/v2/pkg/client/informers/externalversions"
+)
+
+func newMPIJobArray(name string, params ...[]corev1.EnvVar) *kubeflow.MPIJobArray {
+	array := &kubeflow.MPIJobArray{
+		TypeMeta: metav1.TypeMeta{APIVersion: kubeflow.SchemeGroupVersion.String(), Kind: "MPIJobArray"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			UID:       "array-uid",
+		},
+		Spec: kubeflow.MPIJobArraySpec{
+			JobTemplate: kubeflow.MPIJobTemplateSpec{
+				Spec: newMPIJob("tmpl", newInt32(2), nil, nil).Spec,
+			},
+		},
+	}
+	for _, env := range params {
+		array.Spec.Parameters = append(array.Spec.Parameters, kubeflow.ParameterSet{Env: env})
+	}
+	return array
+}
+
+func newArrayController(t *testing.T, array *kubeflow.MPIJobArray, jobs ...*kubeflow.MPIJob) (*MPIJobArrayController, *fake.Clientset) {
+	var objects []runtime.Object
+	for _, j := range jobs {
+		objects = append(objects, j)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	// The tracker can't guess the plural of MPIJobArray from its kind.
+	gvr := kubeflow.SchemeGroupVersion.WithResource("mpijobarrays")
+	if err := client.Tracker().Create(gvr, array, array.Namespace); err != nil {
+		t.Fatalf("Failed adding MPIJobArray to tracker: %v", err)
+	}
+	i := informers.NewSharedInformerFactory(client, noResyncPeriodFunc())
+	c := &MPIJobArrayController{
+		kubeflowClient:    client,
+		mpiJobArrayLister: i.Kubeflow().V2beta1().MPIJobArrays().Lister(),
+		mpiJobLister:      i.Kubeflow().V2beta1().MPIJobs().Lister(),
+		queue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "MPIJobArrays"),
+		recorder:          &record.FakeRecorder{},
+	}
+	if err := i.Kubeflow().V2beta1().MPIJobArrays().Informer().GetIndexer().Add(array); err != nil {
+		t.Fatalf("Failed adding MPIJobArray to informer: %v", err)
+	}
+	for _, j := range jobs {
+		if err := i.Kubeflow().V2beta1().MPIJobs().Informer().GetIndexer().Add(j); err != nil {
+			t.Fatalf("Failed adding MPIJob to informer: %v", err)
+		}
+	}
+	return c, client
+}
+
+func TestMPIJobArrayCreatesInstances(t *testing.T) {
+	array := newMPIJobArray("sweep",
+		[]corev1.EnvVar{{Name: "LR", Value: "0.1"}},
+		[]corev1.EnvVar{{Name: "LR", Value: "0.01"}})
+	launcherSpec := array.Spec.JobTemplate.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeLauncher]
+	launcherSpec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "LR", Value: "1"}, {Name: "EPOCHS", Value: "3"}}
+	c, client := newArrayController(t, array)
+	if err := c.syncMPIJobArray(array); err != nil {
+		t.Fatalf("Failed syncing MPIJobArray: %v", err)
+	}
+
+	var created []*kubeflow.MPIJob
+	var updated *kubeflow.MPIJobArray
+	for _, a := range client.Actions() {
+		switch a.GetVerb() {
+		case "create":
+			created = append(created, a.(core.CreateAction).GetObject().(*kubeflow.MPIJob))
+		case "update":
+			updated = a.(core.UpdateAction).GetObject().(*kubeflow.MPIJobArray)
+		}
+	}
+	if len(created) != 2 {
+		t.Fatalf("Created %d MPIJobs, want 2", len(created))
+	}
+	for i, job := range created {
+		if want := mpiJobArrayInstanceName(array, i); job.Name != want {
+			t.Errorf("Created MPIJob %s, want %s", job.Name, want)
+		}
+		if !metav1.IsControlledBy(job, array) {
+			t.Errorf("MPIJob %s is not controlled by the MPIJobArray", job.Name)
+		}
+		value := array.Spec.Parameters[i].Env[0].Value
+		wantLauncherEnv := []corev1.EnvVar{
+			{Name: "LR", Value: value},
+			{Name: "EPOCHS", Value: "3"},
+			{Name: arrayIndexEnv, Value: job.Labels[arrayIndexLabel]},
+		}
+		gotLauncherEnv := job.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeLauncher].Template.Spec.Containers[0].Env
+		if diff := cmp.Diff(wantLauncherEnv, gotLauncherEnv); diff != "" {
+			t.Errorf("Unexpected launcher env for %s (-want,+got):\n%s", job.Name, diff)
+		}
+		wantWorkerEnv := []corev1.EnvVar{
+			{Name: arrayIndexEnv, Value: job.Labels[arrayIndexLabel]},
+			{Name: "LR", Value: value},
+		}
+		gotWorkerEnv := job.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeWorker].Template.Spec.Containers[0].Env
+		if diff := cmp.Diff(wantWorkerEnv, gotWorkerEnv); diff != "" {
+			t.Errorf("Unexpected worker env for %s (-want,+got):\n%s", job.Name, diff)
+		}
+	}
+	if updated == nil {
+		t.Fatalf("MPIJobArray status wasn't updated")
+	}
+	wantStatus := kubeflow.MPIJobArrayStatus{Active: 2}
+	if diff := cmp.Diff(wantStatus, updated.Status); diff != "" {
+		t.Errorf("Unexpected status (-want,+got):\n%s", diff)
+	}
+}
+
+func TestMPIJobArrayCompletes(t *testing.T) {
+	array := newMPIJobArray("sweep", nil, nil, nil)
+	var jobs []*kubeflow.MPIJob
+	for i, cond := range []common.JobConditionType{common.JobSucceeded, common.JobFailed, common.JobSucceeded} {
+		job := newMPIJobArrayInstance(array, i)
+		job.Status.Conditions = []common.JobCondition{newCondition(cond, "", "")}
+		jobs = append(jobs, job)
+	}
+	c, client := newArrayController(t, array, jobs...)
+	if err := c.syncMPIJobArray(array); err != nil {
+		t.Fatalf("Failed syncing MPIJobArray: %v", err)
+	}
+
+	actions := client.Actions()
+	if len(actions) != 1 || actions[0].GetVerb() != "update" {
+		t.Fatalf("Got actions %v, want a single status update", actions)
+	}
+	got := actions[0].(core.UpdateAction).GetObject().(*kubeflow.MPIJobArray).Status
+	if got.Succeeded != 2 || got.Failed != 1 || got.Active != 0 {
+		t.Errorf("Got %d succeeded, %d failed and %d active MPIJobs, want 2, 1 and 0", got.Succeeded, got.Failed, got.Active)
+	}
+	if got.CompletionTime == nil {
+		t.Errorf("MPIJobArray doesn't have a completion time")
+	}
+}
+
+func TestMPIJobArrayInvalidInstance(t *testing.T) {
+	array := newMPIJobArray("sweep", nil, nil)
+	// Every instance is invalid without launcher containers.
+	array.Spec.JobTemplate.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeLauncher].Template.Spec.Containers = nil
+	c, client := newArrayController(t, array)
+	if err := c.syncMPIJobArray(array); err != nil {
+		t.Fatalf("Failed syncing MPIJobArray: %v", err)
+	}
+
+	var updated *kubeflow.MPIJobArray
+	for _, a := range client.Actions() {
+		switch a.GetVerb() {
+		case "create":
+			t.Errorf("Created invalid MPIJob %s", a.(core.CreateAction).GetObject().(*kubeflow.MPIJob).Name)
+		case "update":
+			updated = a.(core.UpdateAction).GetObject().(*kubeflow.MPIJobArray)
+		}
+	}
+	if updated == nil {
+		t.Fatalf("MPIJobArray status wasn't updated")
+	}
+	if updated.Status.Failed != 2 || updated.Status.CompletionTime == nil {
+		t.Errorf("Got %d failed MPIJobs and completion time %v, want 2 failed and completed", updated.Status.Failed, updated.Status.CompletionTime)
+	}
+}
+
+func TestMPIJobArrayInstanceNotControlledByUs(t *testing.T) {
+	array := newMPIJobArray("sweep", nil)
+	job := newMPIJobArrayInstance(array, 0)
+	job.OwnerReferences = nil
+	// The informer hasn't observed the MPIJob.
+	c, client := newArrayController(t, array)
+	if err := client.Tracker().Add(job); err != nil {
+		t.Fatalf("Failed adding MPIJob to tracker: %v", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	c.recorder = recorder
+	if err := c.syncMPIJobArray(array); err == nil {
+		t.Fatalf("Syncing MPIJobArray succeeded, want error")
+	}
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, ErrResourceExists) {
+			t.Errorf("Got event %q, want %s", e, ErrResourceExists)
+		}
+	default:
+		t.Errorf("No event was emitted")
+	}
+	for _, a := range client.Actions() {
+		if a.GetVerb() == "update" {
+			t.Errorf("MPIJobArray status was updated")
+		}
+	}
+}
+
+func TestMPIJobArrayHandleMPIJob(t *testing.T) {
+	array := newMPIJobArray("sweep", nil)
+	owned := newMPIJobArrayInstance(array, 0)
+	other := newMPIJob("other", newInt32(1), nil, nil)
+	cases := map[string]struct {
+		obj     interface{}
+		wantKey string
+	}{
+		"owned MPIJob": {
+			obj:     owned,
+			wantKey: "default/sweep",
+		},
+		"deleted owned MPIJob": {
+			obj:     cache.DeletedFinalStateUnknown{Key: "default/sweep-0", Obj: owned},
+			wantKey: "default/sweep",
+		},
+		"MPIJob without controller": {
+			obj: other,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newArrayController(t, array)
+			c.handleMPIJob(tc.obj)
+			if tc.wantKey == "" {
+				if c.queue.Len() != 0 {
+					t.Errorf("Enqueued %d keys, want none", c.queue.Len())
+				}
+				return
+			}
+			if item, _ := c.queue.Get(); item != tc.wantKey {
+				t.Errorf("Enqueued %v, want %s", item, tc.wantKey)
+			}
+		})
+	}
+}
+
+func TestMPIJobArrayInvalidNotRequeued(t *testing.T) {
+	array := newMPIJobArray("sweep")
+	c, _ := newArrayController(t, array)
+	recorder := record.NewFakeRecorder(10)
+	c.recorder = recorder
+
+	c.queue.Add("default/sweep")
+	if !processNextKey(c.queue, c.syncHandler) {
+		t.Fatalf("Queue was shut down")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Emitted %d events, want a single validation error", len(recorder.Events))
+	}
+	if c.queue.Len() != 0 || c.queue.NumRequeues("default/sweep") != 0 {
+		t.Errorf("Invalid MPIJobArray was requeued")
+	}
+}
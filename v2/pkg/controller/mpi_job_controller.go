@@ -23,9 +23,11 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"net"
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -38,6 +40,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	batchinformers "k8s.io/client-go/informers/batch/v1"
@@ -113,8 +116,19 @@ const (
 	// uses when the backoff limit is exceeded.
 	jobBackoffLimitExceededReason = "BackoffLimitExceeded"
 
-	openMPISlotsEnv  = "OMPI_MCA_orte_set_default_slots"
-	intelMPISlotsEnv = "I_MPI_PERHOST"
+	openMPISlotsEnv       = "OMPI_MCA_orte_set_default_slots"
+	openMPIRshArgsEnv     = "OMPI_MCA_plm_rsh_args"
+	intelMPISlotsEnv      = "I_MPI_PERHOST"
+	intelBootstrapArgsEnv = "I_MPI_HYDRA_BOOTSTRAP_EXEC_EXTRA_ARGS"
+	hydraLaunchArgsEnv    = "HYDRA_LAUNCH_EXTRA_ARGS"
+	// charmRemoteShellEnv is the command that charmrun uses to start the
+	// processes in the workers, split at blanks, unless ++remote-shell is
+	// passed.
+	charmRemoteShellEnv = "CONV_RSH"
+
+	// defaultSSHConnectionAttempts allows the SSH client used by the MPI
+	// launcher to tolerate workers whose sshd is not ready yet.
+	defaultSSHConnectionAttempts = 10
 )
 
 var (
@@ -184,19 +198,19 @@ var (
 			Name:  "OMPI_MCA_orte_default_hostfile",
 			Value: fmt.Sprintf("%s/%s", configMountPath, hostfileName),
 		},
-		{
-			Name:  "OMPI_MCA_plm_rsh_args",
-			Value: "-o ConnectionAttempts=10",
-		},
 	}
 	intelEnvVars = []corev1.EnvVar{
 		{
 			Name:  "I_MPI_HYDRA_HOST_FILE",
 			Value: fmt.Sprintf("%s/%s", configMountPath, hostfileName),
 		},
+	}
+	charmEnvVars = []corev1.EnvVar{
+		// charmrun reads the nodelist from this file unless ++nodelist is
+		// passed.
 		{
-			Name:  "I_MPI_HYDRA_BOOTSTRAP_EXEC_EXTRA_ARGS",
-			Value: "-o ConnectionAttempts=10",
+			Name:  "NODELIST",
+			Value: fmt.Sprintf("%s/%s", configMountPath, hostfileName),
 		},
 	}
 	nvidiaDisableEnvVars = []corev1.EnvVar{
@@ -228,6 +242,13 @@ type MPIJobController struct {
 	podgroupsSynced cache.InformerSynced
 	mpiJobLister    listers.MPIJobLister
 	mpiJobSynced    cache.InformerSynced
+	// The MPIJobTemplate lister is nil if the MPIJobTemplate CRD isn't
+	// installed.
+	mpiJobTemplateLister listers.MPIJobTemplateLister
+	mpiJobTemplateSynced cache.InformerSynced
+	// The Node lister is nil if the hosts are not ordered by topology.
+	nodeLister corelisters.NodeLister
+	nodeSynced cache.InformerSynced
 
 	// queue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
@@ -241,6 +262,34 @@ type MPIJobController struct {
 	// Gang scheduler name to use
 	gangSchedulerName string
 
+	// hostNetworkPorts is the range of host ports allocated to jobs whose
+	// workers use the host network. Allocation is disabled if empty.
+	hostNetworkPorts utilnet.PortRange
+	ports            *portAllocator
+	// workerSizes tracks the number of workers of each job to report when
+	// it's expanded or shrunk.
+	workerSizes *workerSizes
+
+	// topologyKeys are the node labels, from the outermost level, by which
+	// the workers are ordered in the hostfile and discover_hosts.sh.
+	topologyKeys []string
+
+	// slotsResource is the resource, requested by the workers, that sets the
+	// slots per worker of the MPIJobs that don't specify them. Unused if
+	// empty.
+	slotsResource corev1.ResourceName
+
+	// cleanups tracks the deleted MPIJobs waiting for the cleanup finalizer
+	// to be removed.
+	cleanups *cleanupTracker
+
+	// lookupHost resolves worker hostnames before the launcher is created.
+	// The check is disabled if nil.
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	// workerHosts tracks the worker hostnames being resolved or already
+	// resolved, so that syncs don't wait for DNS.
+	workerHosts *workerHosts
+
 	// To allow injection of updateStatus for testing.
 	updateStatusHandler func(mpijob *kubeflow.MPIJob) error
 }
@@ -257,11 +306,18 @@ func NewMPIJobController(
 	podInformer coreinformers.PodInformer,
 	podgroupsInformer podgroupsinformer.PodGroupInformer,
 	mpiJobInformer informers.MPIJobInformer,
-	gangSchedulerName string) *MPIJobController {
+	mpiJobTemplateInformer informers.MPIJobTemplateInformer,
+	nodeInformer coreinformers.NodeInformer,
+	gangSchedulerName string,
+	hostNetworkPorts utilnet.PortRange,
+	waitForWorkerDNS bool,
+	topologyKeys []string,
+	slotsResource corev1.ResourceName,
+	eventOptions record.CorrelatorOptions) *MPIJobController {
 
 	// Create event broadcaster.
 	klog.V(4).Info("Creating event broadcaster")
-	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster := record.NewBroadcasterWithCorrelatorOptions(eventOptions)
 	eventBroadcaster.StartLogging(klog.Infof)
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
@@ -294,17 +350,46 @@ func NewMPIJobController(
 		queue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "MPIJobs"),
 		recorder:          recorder,
 		gangSchedulerName: gangSchedulerName,
+		hostNetworkPorts:  hostNetworkPorts,
+		ports:             newPortAllocator(),
+		workerSizes:       newWorkerSizes(),
+		workerHosts:       newWorkerHosts(),
+		slotsResource:     slotsResource,
+		cleanups:          newCleanupTracker(),
 	}
 
 	controller.updateStatusHandler = controller.doUpdateJobStatus
+	if mpiJobTemplateInformer != nil {
+		controller.mpiJobTemplateLister = mpiJobTemplateInformer.Lister()
+		controller.mpiJobTemplateSynced = mpiJobTemplateInformer.Informer().HasSynced
+		mpiJobTemplateInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: controller.enqueueTemplateUsers,
+			UpdateFunc: func(old, new interface{}) {
+				controller.enqueueTemplateUsers(new)
+			},
+		})
+	}
+	if nodeInformer != nil {
+		controller.nodeLister = nodeInformer.Lister()
+		controller.nodeSynced = nodeInformer.Informer().HasSynced
+		controller.topologyKeys = topologyKeys
+	}
+	if waitForWorkerDNS {
+		controller.lookupHost = net.DefaultResolver.LookupHost
+	}
 
 	klog.Info("Setting up event handlers")
 	// Set up an event handler for when MPIJob resources change.
 	mpiJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: controller.addMPIJob,
+		AddFunc: func(obj interface{}) {
+			controller.addMPIJob(obj)
+			controller.enqueueDependents(obj)
+		},
 		UpdateFunc: func(old, new interface{}) {
 			controller.enqueueMPIJob(new)
+			controller.enqueueDependents(new)
 		},
+		DeleteFunc: controller.deleteMPIJob,
 	})
 
 	// Set up an event handler for when dependent resources change. This
@@ -369,6 +454,16 @@ func (c *MPIJobController) Run(threadiness int, stopCh <-chan struct{}) error {
 			return fmt.Errorf("failed to wait for podgroup caches to sync")
 		}
 	}
+	if c.mpiJobTemplateSynced != nil {
+		if ok := cache.WaitForCacheSync(stopCh, c.mpiJobTemplateSynced); !ok {
+			return fmt.Errorf("failed to wait for MPIJobTemplate caches to sync")
+		}
+	}
+	if c.nodeSynced != nil {
+		if ok := cache.WaitForCacheSync(stopCh, c.nodeSynced); !ok {
+			return fmt.Errorf("failed to wait for Node caches to sync")
+		}
+	}
 
 	klog.Info("Starting workers")
 	// Launch workers to process MPIJob resources.
@@ -467,6 +562,10 @@ func (c *MPIJobController) syncHandler(key string) error {
 		// The MPIJob may no longer exist, in which case we stop processing.
 		if errors.IsNotFound(err) {
 			klog.V(4).Infof("MPIJob has been deleted: %v", key)
+			c.cleanups.done(key)
+			c.ports.release(key)
+			c.workerSizes.release(key)
+			c.workerHosts.release(key)
 			return nil
 		}
 		return fmt.Errorf("obtaining job: %w", err)
@@ -476,13 +575,26 @@ func (c *MPIJobController) syncHandler(key string) error {
 	// You can use DeepCopy() to make a deep copy of original object and modify this copy
 	// Or create a copy manually for better performance
 	mpiJob := sharedJob.DeepCopy()
-	// Set default for the new mpiJob.
-	scheme.Scheme.Default(mpiJob)
-
-	// for mpi job that is terminating, just return.
+	// for mpi job that is terminating, only clean up its resources.
 	if mpiJob.DeletionTimestamp != nil {
-		return nil
+		return c.finalizeMPIJob(mpiJob)
 	}
+	if mpiJob.Spec.TemplateName != "" {
+		found, err := c.applyTemplate(mpiJob)
+		if err != nil {
+			return err
+		}
+		if !found {
+			// The MPIJob is synced again when the template is created or
+			// updated, or when the recorded template spec is observed.
+			return nil
+		}
+	}
+	if c.slotsResource != "" {
+		setSlotsFromResource(mpiJob, c.slotsResource)
+	}
+	// Set default for the new mpiJob.
+	scheme.Scheme.Default(mpiJob)
 
 	if errs := validation.ValidateMPIJob(mpiJob); len(errs) != 0 {
 		msg := truncateMessage(fmt.Sprintf("Found validation errors: %v", errs.ToAggregate()))
@@ -491,6 +603,15 @@ func (c *MPIJobController) syncHandler(key string) error {
 		return nil
 	}
 
+	if !hasCleanupFinalizer(mpiJob) {
+		// The job is synced again once the finalizer is observed.
+		return c.addCleanupFinalizer(mpiJob)
+	}
+	if needsChildNamePrefix(mpiJob) {
+		// The job is synced again once the prefix is observed.
+		return c.recordChildNamePrefix(mpiJob)
+	}
+
 	if len(mpiJob.Status.Conditions) == 0 {
 		msg := fmt.Sprintf("MPIJob %s/%s is created.", mpiJob.Namespace, mpiJob.Name)
 		updateMPIJobConditions(mpiJob, common.JobCreated, mpiJobCreatedReason, msg)
@@ -502,6 +623,15 @@ func (c *MPIJobController) syncHandler(key string) error {
 	// retrying (it reached .spec.backoffLimit). If it's filled, we want to
 	// cleanup and stop retrying the MPIJob.
 	if isFinished(mpiJob.Status) && mpiJob.Status.CompletionTime != nil {
+		if ttl := mpiJob.Spec.RunPolicy.TTLSecondsAfterFinished; ttl != nil {
+			left := time.Duration(*ttl)*time.Second - time.Since(mpiJob.Status.CompletionTime.Time)
+			if left <= 0 {
+				// Clean up all the resources, including the workers kept by
+				// the clean Pod policy.
+				return c.deleteOwnedResources(mpiJob)
+			}
+			c.queue.AddAfter(key, left)
+		}
 		if isCleanUpPods(mpiJob.Spec.RunPolicy.CleanPodPolicy) {
 			// set worker StatefulSet Replicas to 0.
 			if err := c.deleteWorkerPods(mpiJob); err != nil {
@@ -519,6 +649,32 @@ func (c *MPIJobController) syncHandler(key string) error {
 		return nil
 	}
 
+	if left, ok := deadlineRemaining(mpiJob, time.Now()); ok {
+		launcher, err := c.getLauncherJob(mpiJob)
+		if err != nil {
+			return err
+		}
+		if launcher == nil {
+			if left <= 0 {
+				return c.failPastDeadline(mpiJob)
+			}
+			c.queue.AddAfter(key, left)
+		}
+	}
+
+	if mpiJob.Status.StartTime == nil && len(mpiJob.Spec.DependsOn) > 0 {
+		ready, err := c.checkDependencies(mpiJob)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			if !reflect.DeepEqual(sharedJob.Status, mpiJob.Status) {
+				return c.updateStatusHandler(mpiJob)
+			}
+			return nil
+		}
+	}
+
 	// first set StartTime.
 	if mpiJob.Status.StartTime == nil {
 		now := metav1.Now()
@@ -530,11 +686,33 @@ func (c *MPIJobController) syncHandler(key string) error {
 	if err != nil {
 		return err
 	}
+	if after, ok := requeueAfter(mpiJob); ok {
+		return c.requeueMPIJob(mpiJob, launcher, after)
+	}
+	if launcher != nil && !isCurrentAttempt(mpiJob, launcher) {
+		// The launcher of a requeued attempt is being deleted.
+		return nil
+	}
+	if launcher != nil && canRequeue(mpiJob, launcher) {
+		return c.recordRequeue(mpiJob, launcher)
+	}
+	if launcher == nil && requeueCount(mpiJob) > 0 {
+		// Wait for the workers of the previous attempt to be gone.
+		terminating, err := c.hasTerminatingWorkers(mpiJob)
+		if err != nil || terminating {
+			return err
+		}
+	}
 
 	var worker []*corev1.Pod
 	// We're done if the launcher either succeeded or failed.
 	done := launcher != nil && isJobFinished(launcher)
 	if !done {
+		if c.hostNetworkPorts.Size > 0 && needsSSHPort(mpiJob) {
+			// The job is synced again once the allocation is observed.
+			return c.allocateSSHPort(mpiJob)
+		}
+
 		_, err := c.getOrCreateService(mpiJob, newWorkersService(mpiJob))
 		if err != nil {
 			return fmt.Errorf("getting or creating Service to front workers: %w", err)
@@ -570,6 +748,19 @@ func (c *MPIJobController) syncHandler(key string) error {
 			}
 		}
 		if launcher == nil {
+			if name := workerWithoutAddress(mpiJob, worker); name != "" {
+				// The update of the worker's network status syncs the job
+				// again.
+				c.recorder.Eventf(mpiJob, corev1.EventTypeNormal, workersWithoutAddressReason, "Waiting for worker %s to report an address in network %s before creating the launcher", name, mpiJob.Spec.HostDiscoveryNetwork)
+				return c.updateMPIJobStatus(mpiJob, launcher, worker)
+			}
+			if c.lookupHost != nil {
+				if fqdn := c.unresolvableWorker(key, mpiJob, worker); fqdn != "" {
+					c.recorder.Eventf(mpiJob, corev1.EventTypeNormal, workersNotResolvableReason, "Waiting for worker %s to be resolvable before creating the launcher", fqdn)
+					c.queue.AddAfter(key, workerDNSRetryInterval)
+					return c.updateMPIJobStatus(mpiJob, launcher, worker)
+				}
+			}
 			launcher, err = c.kubeClient.BatchV1().Jobs(namespace).Create(context.TODO(), c.newLauncherJob(mpiJob), metav1.CreateOptions{})
 			if err != nil {
 				c.recorder.Eventf(mpiJob, corev1.EventTypeWarning, mpiJobFailedReason, "launcher pod created failed: %v", err)
@@ -590,7 +781,7 @@ func (c *MPIJobController) syncHandler(key string) error {
 
 // getLauncherJob gets the launcher Job controlled by this MPIJob.
 func (c *MPIJobController) getLauncherJob(mpiJob *kubeflow.MPIJob) (*batchv1.Job, error) {
-	launcher, err := c.jobLister.Jobs(mpiJob.Namespace).Get(mpiJob.Name + launcherSuffix)
+	launcher, err := c.jobLister.Jobs(mpiJob.Namespace).Get(childName(mpiJob, launcherSuffix))
 	if errors.IsNotFound(err) {
 		return nil, nil
 	}
@@ -690,14 +881,18 @@ func (c *MPIJobController) getRunningWorkerPods(mpiJob *kubeflow.MPIJob) ([]*cor
 // getOrCreateConfigMap gets the ConfigMap controlled by this MPIJob, or creates
 // one if it doesn't exist.
 func (c *MPIJobController) getOrCreateConfigMap(mpiJob *kubeflow.MPIJob) (*corev1.ConfigMap, error) {
-	newCM := newConfigMap(mpiJob, workerReplicas(mpiJob))
 	podList, err := c.getRunningWorkerPods(mpiJob)
 	if err != nil {
 		return nil, err
 	}
-	updateDiscoverHostsInConfigMap(newCM, mpiJob, podList)
+	var topology map[string][]string
+	if c.nodeLister != nil {
+		topology = c.workerTopology(podList)
+	}
+	newCM := newConfigMap(mpiJob, workerReplicas(mpiJob), workerAddresses(mpiJob, podList), topology)
+	updateDiscoverHostsInConfigMap(newCM, mpiJob, podList, topology)
 
-	cm, err := c.configMapLister.ConfigMaps(mpiJob.Namespace).Get(mpiJob.Name + configSuffix)
+	cm, err := c.configMapLister.ConfigMaps(mpiJob.Namespace).Get(childName(mpiJob, configSuffix))
 	// If the ConfigMap doesn't exist, we'll create it.
 	if errors.IsNotFound(err) {
 		return c.kubeClient.CoreV1().ConfigMaps(mpiJob.Namespace).Create(context.TODO(), newCM, metav1.CreateOptions{})
@@ -754,7 +949,7 @@ func (c *MPIJobController) getOrCreateService(job *kubeflow.MPIJob, newSvc *core
 // getOrCreateSSHAuthSecret gets the Secret holding the SSH auth for this job,
 // or create one if it doesn't exist.
 func (c *MPIJobController) getOrCreateSSHAuthSecret(job *kubeflow.MPIJob) (*corev1.Secret, error) {
-	secret, err := c.secretLister.Secrets(job.Namespace).Get(job.Name + sshAuthSecretSuffix)
+	secret, err := c.secretLister.Secrets(job.Namespace).Get(childName(job, sshAuthSecretSuffix))
 	if errors.IsNotFound(err) {
 		secret, err := newSSHAuthSecret(job)
 		if err != nil {
@@ -802,7 +997,13 @@ func (c *MPIJobController) getOrCreateWorker(mpiJob *kubeflow.MPIJob) ([]*corev1
 		return workerPods, nil
 	}
 
-	// Remove Pods when replicas are scaled down
+	key, err := cache.MetaNamespaceKeyFunc(mpiJob)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove Pods when replicas are scaled down. Pods that are already
+	// terminating are left alone.
 	selector, err := workerSelector(mpiJob.Name)
 	if err != nil {
 		return nil, err
@@ -811,8 +1012,10 @@ func (c *MPIJobController) getOrCreateWorker(mpiJob *kubeflow.MPIJob) ([]*corev1
 	if err != nil {
 		return nil, err
 	}
-	if len(podFullList) > int(*worker.Replicas) {
-		for _, pod := range podFullList {
+	livePods := liveWorkers(podFullList)
+	prevSize, knownSize := c.previousWorkerSize(key, len(livePods), *worker.Replicas)
+	if len(livePods) > int(*worker.Replicas) {
+		for _, pod := range livePods {
 			indexStr, ok := pod.Labels[common.ReplicaIndexLabel]
 			if !ok {
 				return nil, err
@@ -854,12 +1057,13 @@ func (c *MPIJobController) getOrCreateWorker(mpiJob *kubeflow.MPIJob) ([]*corev1
 		workerPods = append(workerPods, pod)
 	}
 
+	c.recordWorkerSize(mpiJob, key, prevSize, knownSize, *worker.Replicas)
 	return workerPods, nil
 }
 
 func (c *MPIJobController) deleteWorkerPods(mpiJob *kubeflow.MPIJob) error {
 	var (
-		workerPrefix       = mpiJob.Name + workerSuffix
+		workerPrefix       = childName(mpiJob, workerSuffix)
 		i            int32 = 0
 	)
 	worker := mpiJob.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeWorker]
@@ -901,14 +1105,15 @@ func (c *MPIJobController) deleteWorkerPods(mpiJob *kubeflow.MPIJob) error {
 
 func (c *MPIJobController) updateMPIJobStatus(mpiJob *kubeflow.MPIJob, launcher *batchv1.Job, worker []*corev1.Pod) error {
 	oldStatus := mpiJob.Status.DeepCopy()
-	launcherPods, err := c.jobPods(launcher)
-	if err != nil {
-		return fmt.Errorf("checking launcher pods running: %w", err)
-	}
-	// Job.status.Active accounts for Pending and Running pods. Count running pods
-	// from the lister instead.
-	launcherPodsCnt := countRunningPods(launcherPods)
+	launcherPodsCnt := 0
 	if launcher != nil {
+		launcherPods, err := c.jobPods(launcher)
+		if err != nil {
+			return fmt.Errorf("checking launcher pods running: %w", err)
+		}
+		// Job.status.Active accounts for Pending and Running pods. Count running pods
+		// from the lister instead.
+		launcherPodsCnt = countRunningPods(launcherPods)
 		initializeMPIJobStatuses(mpiJob, kubeflow.MPIReplicaTypeLauncher)
 		launcherStatus := mpiJob.Status.ReplicaStatuses[common.ReplicaType(kubeflow.MPIReplicaTypeLauncher)]
 		launcherStatus.Failed = launcher.Status.Failed
@@ -1025,6 +1230,17 @@ func (c *MPIJobController) enqueueMPIJob(obj interface{}) {
 	c.queue.AddRateLimited(key)
 }
 
+// deleteMPIJob enqueues a deleted MPIJob, so that the sync forgets the state
+// kept for it by the controller.
+func (c *MPIJobController) deleteMPIJob(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
 // handleObject will take any resource implementing metav1.Object and attempt
 // to find the MPIJob resource that 'owns' it. It does this by looking at the
 // objects metadata.ownerReferences field for an appropriate OwnerReference.
@@ -1101,25 +1317,40 @@ func (c *MPIJobController) doUpdateJobStatus(mpiJob *kubeflow.MPIJob) error {
 }
 
 // newConfigMap creates a new ConfigMap containing configurations for an MPIJob
-// resource. It also sets the appropriate OwnerReferences on the resource so
+// resource. Workers with an entry in addresses are listed by that address
+// instead of their hostname, and workers are ordered by their location in
+// topology. It also sets the appropriate OwnerReferences on the resource so
 // handleObject can discover the MPIJob resource that 'owns' it.
-func newConfigMap(mpiJob *kubeflow.MPIJob, workerReplicas int32) *corev1.ConfigMap {
+func newConfigMap(mpiJob *kubeflow.MPIJob, workerReplicas int32, addresses map[string]string, topology map[string][]string) *corev1.ConfigMap {
 	var buffer bytes.Buffer
-	workersService := mpiJob.Name + workerSuffix
-    slots := 1
+	workersService := childName(mpiJob, workerSuffix)
+	slots := 1
 	if mpiJob.Spec.SlotsPerWorker != nil {
 		slots = int(*mpiJob.Spec.SlotsPerWorker)
 	}
-	for i := 0; i < int(workerReplicas); i++ {
-		buffer.WriteString(fmt.Sprintf("host %s%s-%d.%s ++cpus %d\n", mpiJob.Name, workerSuffix, i, workersService, slots))
+	generator := hostfileGeneratorFor(mpiJob.Spec.MPIImplementation)
+	names := make([]string, workerReplicas)
+	for i := range names {
+		names[i] = workerName(mpiJob, i)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return lessByTopology(topology, names[i], names[j])
+	})
+	for _, name := range names {
+		host, ok := addresses[name]
+		if !ok {
+			host = fmt.Sprintf("%s.%s", name, workersService)
+		}
+		buffer.WriteString(generator.hostEntry(host, slots))
 	}
 
-	return &corev1.ConfigMap{
+	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      mpiJob.Name + configSuffix,
+			Name:      childName(mpiJob, configSuffix),
 			Namespace: mpiJob.Namespace,
 			Labels: map[string]string{
-				"app": mpiJob.Name,
+				"app":                    mpiJob.Name,
+				common.OperatorNameLabel: kubeflow.OperatorName,
 			},
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(mpiJob, kubeflow.SchemeGroupVersionKind),
@@ -1129,19 +1360,29 @@ func newConfigMap(mpiJob *kubeflow.MPIJob, workerReplicas int32) *corev1.ConfigM
 			hostfileName: buffer.String(),
 		},
 	}
+	applyMetadataPolicy(mpiJob, &cm.ObjectMeta)
+	return cm
 }
 
 // updateDiscoverHostsInConfigMap updates the ConfigMap if the content of `discover_hosts.sh` changes.
-func updateDiscoverHostsInConfigMap(configMap *corev1.ConfigMap, mpiJob *kubeflow.MPIJob, runningPods []*corev1.Pod) {
+func updateDiscoverHostsInConfigMap(configMap *corev1.ConfigMap, mpiJob *kubeflow.MPIJob, runningPods []*corev1.Pod, topology map[string][]string) {
 	// Sort the slice of Pods to make sure the order of entries in `discover_hosts.sh` is maintained.
 	sort.Slice(runningPods, func(i, j int) bool {
 		return runningPods[i].Name < runningPods[j].Name
 	})
+	sort.SliceStable(runningPods, func(i, j int) bool {
+		return lessByTopology(topology, runningPods[i].Name, runningPods[j].Name)
+	})
 
 	var buffer bytes.Buffer
 	buffer.WriteString("#!/bin/sh\n")
-	workersService := mpiJob.Name + workerSuffix
+	workersService := childName(mpiJob, workerSuffix)
+	addresses := workerAddresses(mpiJob, runningPods)
 	for _, p := range runningPods {
+		if addr, ok := addresses[p.Name]; ok {
+			buffer.WriteString(fmt.Sprintf("echo %s\n", addr))
+			continue
+		}
 		buffer.WriteString(fmt.Sprintf("echo %s.%s.%s.svc\n", p.Name, workersService, p.Namespace))
 	}
 
@@ -1150,21 +1391,22 @@ func updateDiscoverHostsInConfigMap(configMap *corev1.ConfigMap, mpiJob *kubeflo
 
 // newWorkersService creates a new workers' Service for an MPIJob resource.
 func newWorkersService(job *kubeflow.MPIJob) *corev1.Service {
-	return newService(job, job.Name+workerSuffix, defaultLabels(job.Name, worker))
+	return newService(job, childName(job, workerSuffix), defaultLabels(job.Name, worker))
 }
 
 // newLauncherService creates a new launcher's Service for an MPIJob resource.
 func newLauncherService(job *kubeflow.MPIJob) *corev1.Service {
-	return newService(job, job.Name+launcherSuffix, defaultLabels(job.Name, launcher))
+	return newService(job, childName(job, launcherSuffix), defaultLabels(job.Name, launcher))
 }
 
 func newService(job *kubeflow.MPIJob, name string, selector map[string]string) *corev1.Service {
-	return &corev1.Service{
+	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: job.Namespace,
 			Labels: map[string]string{
-				"app": job.Name,
+				"app":                    job.Name,
+				common.OperatorNameLabel: kubeflow.OperatorName,
 			},
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(job, kubeflow.SchemeGroupVersionKind),
@@ -1175,6 +1417,8 @@ func newService(job *kubeflow.MPIJob, name string, selector map[string]string) *
 			Selector:  selector,
 		},
 	}
+	applyMetadataPolicy(job, &svc.ObjectMeta)
+	return svc
 }
 
 // newSSHAuthSecret creates a new Secret that holds SSH auth: a private Key
@@ -1197,12 +1441,13 @@ func newSSHAuthSecret(job *kubeflow.MPIJob) (*corev1.Secret, error) {
 	if err != nil {
 		return nil, fmt.Errorf("generating public SSH key: %w", err)
 	}
-	return &corev1.Secret{
+	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      job.Name + sshAuthSecretSuffix,
+			Name:      childName(job, sshAuthSecretSuffix),
 			Namespace: job.Namespace,
 			Labels: map[string]string{
-				"app": job.Name,
+				"app":                    job.Name,
+				common.OperatorNameLabel: kubeflow.OperatorName,
 			},
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(job, kubeflow.SchemeGroupVersionKind),
@@ -1213,7 +1458,9 @@ func newSSHAuthSecret(job *kubeflow.MPIJob) (*corev1.Secret, error) {
 			corev1.SSHAuthPrivateKey: privatePEM,
 			sshPublicKey:             ssh.MarshalAuthorizedKey(publicKey),
 		},
-	}, nil
+	}
+	applyMetadataPolicy(job, &secret.ObjectMeta)
+	return secret, nil
 }
 
 // newPodGroup creates a new PodGroup for an MPIJob
@@ -1227,7 +1474,7 @@ func newPodGroup(mpiJob *kubeflow.MPIJob, minAvailableReplicas int32) *podgroupv
 			pName = w.Template.Spec.PriorityClassName
 		}
 	}
-	return &podgroupv1beta1.PodGroup{
+	pg := &podgroupv1beta1.PodGroup{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      mpiJob.Name,
 			Namespace: mpiJob.Namespace,
@@ -1241,10 +1488,12 @@ func newPodGroup(mpiJob *kubeflow.MPIJob, minAvailableReplicas int32) *podgroupv
 			PriorityClassName: pName,
 		},
 	}
+	applyMetadataPolicy(mpiJob, &pg.ObjectMeta)
+	return pg
 }
 
 func workerName(mpiJob *kubeflow.MPIJob, index int) string {
-	return fmt.Sprintf("%s%s-%d", mpiJob.Name, workerSuffix, index)
+	return fmt.Sprintf("%s-%d", childName(mpiJob, workerSuffix), index)
 }
 
 // newWorker creates a new worker StatefulSet for an MPIJob resource. It also
@@ -1263,8 +1512,9 @@ func (c *MPIJobController) newWorker(mpiJob *kubeflow.MPIJob, index int) *corev1
 		podTemplate.Labels[key] = value
 	}
 	podTemplate.Labels[common.ReplicaIndexLabel] = strconv.Itoa(index)
+	setNetworksAnnotation(podTemplate, mpiJob)
 	podTemplate.Spec.Hostname = name
-	podTemplate.Spec.Subdomain = mpiJob.Name + workerSuffix // Matches workers' Service name.
+	podTemplate.Spec.Subdomain = childName(mpiJob, workerSuffix) // Matches workers' Service name.
 	if podTemplate.Spec.HostNetwork {
 		// Allows resolution of worker hostnames without needing to include the
 		// namespace or cluster domain.
@@ -1273,11 +1523,22 @@ func (c *MPIJobController) newWorker(mpiJob *kubeflow.MPIJob, index int) *corev1
 	setRestartPolicy(podTemplate, mpiJob.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeWorker])
 
 	container := &podTemplate.Spec.Containers[0]
+	sshPort, hasSSHPort := sshPortFromAnnotations(mpiJob)
 	if len(container.Command) == 0 && len(container.Args) == 0 {
 		container.Command = []string{"/usr/sbin/sshd", "-De"}
+		if hasSSHPort {
+			container.Command = append(container.Command, "-p", strconv.Itoa(int(sshPort)))
+		}
 	}
 	container.Env = append(container.Env, workerEnvVars...)
+	if hasSSHPort {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  sshPortEnv,
+			Value: strconv.Itoa(int(sshPort)),
+		})
+	}
 	c.setupSSHOnPod(&podTemplate.Spec, mpiJob)
+	setupProfiling(&podTemplate.Spec, mpiJob, false)
 
 	// add SchedulerName to podSpec
 	if c.gangSchedulerName != "" {
@@ -1292,6 +1553,7 @@ func (c *MPIJobController) newWorker(mpiJob *kubeflow.MPIJob, index int) *corev1
 		// we create the podGroup with the same name as the mpijob
 		podTemplate.Annotations[podgroupv1beta1.KubeGroupNameAnnotationKey] = mpiJob.Name
 	}
+	applyMetadataPolicy(mpiJob, &podTemplate.ObjectMeta)
 
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1308,31 +1570,35 @@ func (c *MPIJobController) newWorker(mpiJob *kubeflow.MPIJob, index int) *corev1
 }
 
 func (c *MPIJobController) newLauncherJob(mpiJob *kubeflow.MPIJob) *batchv1.Job {
-	return &batchv1.Job{
+	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      mpiJob.Name + launcherSuffix,
+			Name:      childName(mpiJob, launcherSuffix),
 			Namespace: mpiJob.Namespace,
 			Labels: map[string]string{
-				"app": mpiJob.Name,
+				"app":                    mpiJob.Name,
+				common.OperatorNameLabel: kubeflow.OperatorName,
 			},
+			Annotations: launcherAnnotations(mpiJob),
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(mpiJob, kubeflow.SchemeGroupVersionKind),
 			},
 		},
 		Spec: batchv1.JobSpec{
 			TTLSecondsAfterFinished: mpiJob.Spec.RunPolicy.TTLSecondsAfterFinished,
-			ActiveDeadlineSeconds:   mpiJob.Spec.RunPolicy.ActiveDeadlineSeconds,
+			ActiveDeadlineSeconds:   launcherActiveDeadline(mpiJob, time.Now()),
 			BackoffLimit:            mpiJob.Spec.RunPolicy.BackoffLimit,
 			Template:                c.newLauncherPodTemplate(mpiJob),
 		},
 	}
+	applyMetadataPolicy(mpiJob, &job.ObjectMeta)
+	return job
 }
 
 // newLauncherPodTemplate creates a new launcher Job for an MPIJob resource. It also sets
 // the appropriate OwnerReferences on the resource so handleObject can discover
 // the MPIJob resource that 'owns' it.
 func (c *MPIJobController) newLauncherPodTemplate(mpiJob *kubeflow.MPIJob) corev1.PodTemplateSpec {
-	launcherName := mpiJob.Name + launcherSuffix
+	launcherName := childName(mpiJob, launcherSuffix)
 
 	podTemplate := mpiJob.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeLauncher].Template.DeepCopy()
 	// copy the labels and annotations to pod from PodTemplate
@@ -1342,6 +1608,7 @@ func (c *MPIJobController) newLauncherPodTemplate(mpiJob *kubeflow.MPIJob) corev
 	for key, value := range defaultLabels(mpiJob.Name, launcher) {
 		podTemplate.Labels[key] = value
 	}
+	setNetworksAnnotation(podTemplate, mpiJob)
 	// add SchedulerName to podSpec
 	if c.gangSchedulerName != "" {
 		if podTemplate.Spec.SchedulerName != "" && podTemplate.Spec.SchedulerName != c.gangSchedulerName {
@@ -1356,7 +1623,7 @@ func (c *MPIJobController) newLauncherPodTemplate(mpiJob *kubeflow.MPIJob) corev
 		podTemplate.Annotations[podgroupv1beta1.KubeGroupNameAnnotationKey] = mpiJob.Name
 	}
 	podTemplate.Spec.Hostname = launcherName
-	podTemplate.Spec.Subdomain = mpiJob.Name + workerSuffix // Matches workers' Service name.
+	podTemplate.Spec.Subdomain = childName(mpiJob, workerSuffix) // Matches workers' Service name.
 	if podTemplate.Spec.HostNetwork {
 		// Allows resolution of worker hostnames without needing to include the
 		// namespace or cluster domain.
@@ -1365,18 +1632,47 @@ func (c *MPIJobController) newLauncherPodTemplate(mpiJob *kubeflow.MPIJob) corev
 	container := &podTemplate.Spec.Containers[0]
 	container.Env = append(container.Env, launcherEnvVars...)
 	slotsStr := strconv.Itoa(int(*mpiJob.Spec.SlotsPerWorker))
+	sshArgs := sshClientArgs(mpiJob)
+	sshPort, hasSSHPort := sshPortFromAnnotations(mpiJob)
 	switch mpiJob.Spec.MPIImplementation {
 	case kubeflow.MPIImplementationOpenMPI:
 		container.Env = append(container.Env, ompiEnvVars...)
-		container.Env = append(container.Env, corev1.EnvVar{
-			Name:  openMPISlotsEnv,
-			Value: slotsStr,
-		})
+		container.Env = append(container.Env,
+			corev1.EnvVar{
+				Name:  openMPIRshArgsEnv,
+				Value: sshArgs,
+			},
+			corev1.EnvVar{
+				Name:  openMPISlotsEnv,
+				Value: slotsStr,
+			})
 	case kubeflow.MPIImplementationIntel:
 		container.Env = append(container.Env, intelEnvVars...)
+		container.Env = append(container.Env,
+			corev1.EnvVar{
+				Name:  intelBootstrapArgsEnv,
+				Value: sshArgs,
+			},
+			corev1.EnvVar{
+				Name:  hydraLaunchArgsEnv,
+				Value: sshArgs,
+			},
+			corev1.EnvVar{
+				Name:  intelMPISlotsEnv,
+				Value: slotsStr,
+			})
+	case kubeflow.MPIImplementationCharm:
+		// The slots are set per host in the nodelist.
+		container.Env = append(container.Env, charmEnvVars...)
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  charmRemoteShellEnv,
+			Value: "ssh " + sshArgs,
+		})
+	}
+	if hasSSHPort {
 		container.Env = append(container.Env, corev1.EnvVar{
-			Name:  intelMPISlotsEnv,
-			Value: slotsStr,
+			Name:  sshPortEnv,
+			Value: strconv.Itoa(int(sshPort)),
 		})
 	}
 
@@ -1386,6 +1682,7 @@ func (c *MPIJobController) newLauncherPodTemplate(mpiJob *kubeflow.MPIJob) corev
 		// issues with scheduler/container technologies.
 		nvidiaDisableEnvVars...)
 	c.setupSSHOnPod(&podTemplate.Spec, mpiJob)
+	setupProfiling(&podTemplate.Spec, mpiJob, true)
 
 	// Submit a warning event if the user specifies restart policy for
 	// the pod template. We recommend to set it from the replica level.
@@ -1402,7 +1699,7 @@ func (c *MPIJobController) newLauncherPodTemplate(mpiJob *kubeflow.MPIJob) corev
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
 					LocalObjectReference: corev1.LocalObjectReference{
-						Name: mpiJob.Name + configSuffix,
+						Name: childName(mpiJob, configSuffix),
 					},
 					Items: configVolumeItems,
 				},
@@ -1412,6 +1709,7 @@ func (c *MPIJobController) newLauncherPodTemplate(mpiJob *kubeflow.MPIJob) corev
 		Name:      configVolumeName,
 		MountPath: configMountPath,
 	})
+	applyMetadataPolicy(mpiJob, &podTemplate.ObjectMeta)
 
 	return corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1511,6 +1809,37 @@ func defaultLabels(jobName, role string) map[string]string {
 	}
 }
 
+// SelectOwnedObjects restricts the objects listed and watched by an informer
+// to the ones with the operator name label, which the controller sets on all
+// the objects it creates.
+func SelectOwnedObjects(opts *metav1.ListOptions) {
+	opts.LabelSelector = labels.SelectorFromSet(labels.Set{common.OperatorNameLabel: kubeflow.OperatorName}).String()
+}
+
+// applyMetadataPolicy adds the labels and annotations of the job's metadata
+// policy to the object, without overriding the ones already set.
+func applyMetadataPolicy(job *kubeflow.MPIJob, meta *metav1.ObjectMeta) {
+	p := job.Spec.MetadataPolicy
+	if p == nil {
+		return
+	}
+	meta.Labels = addMissing(meta.Labels, p.Labels)
+	meta.Annotations = addMissing(meta.Annotations, p.Annotations)
+}
+
+func addMissing(dst, src map[string]string) map[string]string {
+	for k, v := range src {
+		if _, ok := dst[k]; ok {
+			continue
+		}
+		if dst == nil {
+			dst = make(map[string]string, len(src))
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
 func workerSelector(mpiJobName string) (labels.Selector, error) {
 	set := defaultLabels(mpiJobName, worker)
 	return labels.ValidatedSelectorFromSet(set)
@@ -1524,6 +1853,37 @@ func workerReplicas(job *kubeflow.MPIJob) int32 {
 	return 0
 }
 
+// sshClientArgs returns the arguments for the SSH client that the launcher
+// uses to reach the workers, built from the job's SSH options and allocated
+// SSH port.
+func sshClientArgs(job *kubeflow.MPIJob) string {
+	var opts kubeflow.SSHOptions
+	if job.Spec.SSHOptions != nil {
+		opts = *job.Spec.SSHOptions
+	}
+	attempts := int32(defaultSSHConnectionAttempts)
+	if opts.ConnectionAttempts != nil {
+		attempts = *opts.ConnectionAttempts
+	}
+	args := []string{fmt.Sprintf("-o ConnectionAttempts=%d", attempts)}
+	if opts.ServerAliveInterval != nil {
+		args = append(args, fmt.Sprintf("-o ServerAliveInterval=%d", *opts.ServerAliveInterval))
+	}
+	if opts.ServerAliveCountMax != nil {
+		args = append(args, fmt.Sprintf("-o ServerAliveCountMax=%d", *opts.ServerAliveCountMax))
+	}
+	if len(opts.Ciphers) > 0 {
+		args = append(args, "-o Ciphers="+strings.Join(opts.Ciphers, ","))
+	}
+	if opts.ProxyJump != "" {
+		args = append(args, "-o ProxyJump="+opts.ProxyJump)
+	}
+	if port, ok := sshPortFromAnnotations(job); ok {
+		args = append(args, fmt.Sprintf("-p %d", port))
+	}
+	return strings.Join(args, " ")
+}
+
 func (c *MPIJobController) setupSSHOnPod(podSpec *corev1.PodSpec, job *kubeflow.MPIJob) {
 	var mode *int32
 	if job.Spec.SSHAuthMountPath == rootSSHPath {
@@ -1536,7 +1896,7 @@ func (c *MPIJobController) setupSSHOnPod(podSpec *corev1.PodSpec, job *kubeflow.
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
 					DefaultMode: mode,
-					SecretName:  job.Name + sshAuthSecretSuffix,
+					SecretName:  childName(job, sshAuthSecretSuffix),
 					Items:       sshVolumeItems,
 				},
 			},
@@ -0,0 +1,75 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	common "github.com/kubeflow/common/pkg/apis/common/v1"
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+// jobDeadlineExceededReason matches the reason that the k8s job controller
+// uses when a Job exceeds its active deadline.
+const jobDeadlineExceededReason = "DeadlineExceeded"
+
+// deadlineRemaining returns the time left until the deadline of the job. ok
+// is false if the job has no deadline.
+func deadlineRemaining(job *kubeflow.MPIJob, now time.Time) (left time.Duration, ok bool) {
+	if job.Spec.DeadlineSeconds == nil {
+		return 0, false
+	}
+	deadline := job.CreationTimestamp.Add(time.Duration(*job.Spec.DeadlineSeconds) * time.Second)
+	return deadline.Sub(now), true
+}
+
+// launcherActiveDeadline returns the active deadline for a new launcher Job:
+// the shortest of runPolicy.activeDeadlineSeconds and the time left until
+// the deadline of the job.
+func launcherActiveDeadline(job *kubeflow.MPIJob, now time.Time) *int64 {
+	activeDeadline := job.Spec.RunPolicy.ActiveDeadlineSeconds
+	left, ok := deadlineRemaining(job, now)
+	if !ok {
+		return activeDeadline
+	}
+	secs := int64(math.Ceil(left.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	if activeDeadline != nil && *activeDeadline <= secs {
+		return activeDeadline
+	}
+	return &secs
+}
+
+// failPastDeadline marks a job that exceeded its deadline before its
+// launcher was created as failed. Once the launcher exists, its active
+// deadline enforces the rest of the job deadline.
+func (c *MPIJobController) failPastDeadline(mpiJob *kubeflow.MPIJob) error {
+	msg := fmt.Sprintf("MPIJob %s/%s exceeded its deadline of %d seconds", mpiJob.Namespace, mpiJob.Name, *mpiJob.Spec.DeadlineSeconds)
+	c.recorder.Event(mpiJob, corev1.EventTypeWarning, jobDeadlineExceededReason, msg)
+	if mpiJob.Status.CompletionTime == nil {
+		now := metav1.Now()
+		mpiJob.Status.CompletionTime = &now
+	}
+	updateMPIJobConditions(mpiJob, common.JobFailed, jobDeadlineExceededReason, msg)
+	mpiJobsFailureCount.Inc()
+	return c.updateStatusHandler(mpiJob)
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	common "github.com/kubeflow/common/pkg/apis/common/v1"
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+const (
+	// mpiJobWaiting is the condition of an MPIJob whose dependencies haven't
+	// succeeded yet.
+	mpiJobWaiting common.JobConditionType = "Waiting"
+
+	// dependenciesPendingReason is added in a mpijob when it waits for its
+	// dependencies.
+	dependenciesPendingReason = "DependenciesPending"
+	// dependenciesSucceededReason is added in a mpijob when all its
+	// dependencies succeeded.
+	dependenciesSucceededReason = "DependenciesSucceeded"
+	// dependencyFailedReason is added in a mpijob when one of its
+	// dependencies failed.
+	dependencyFailedReason = "DependencyFailed"
+	// dependencyCycleReason is added in a mpijob when its dependencies
+	// depend on it.
+	dependencyCycleReason = "DependencyCycle"
+)
+
+// checkDependencies updates the Waiting condition of the MPIJob from the
+// state of its dependencies and returns whether the job can start. If a
+// dependency failed or the dependencies form a cycle, the MPIJob is marked as
+// failed.
+func (c *MPIJobController) checkDependencies(job *kubeflow.MPIJob) (bool, error) {
+	cycle, err := c.dependencyCycle(job)
+	if err != nil {
+		return false, err
+	}
+	if cycle != nil {
+		msg := fmt.Sprintf("MPIJob %s/%s failed because its dependencies form a cycle: %s.", job.Namespace, job.Name, strings.Join(cycle, " -> "))
+		c.failOnDependencies(job, dependencyCycleReason, msg)
+		return false, nil
+	}
+	var pending []string
+	for _, name := range job.Spec.DependsOn {
+		dep, err := c.mpiJobLister.MPIJobs(job.Namespace).Get(name)
+		if errors.IsNotFound(err) {
+			pending = append(pending, name)
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("obtaining dependency %s: %w", name, err)
+		}
+		if isFailed(dep.Status) {
+			msg := fmt.Sprintf("MPIJob %s/%s failed because its dependency %s failed.", job.Namespace, job.Name, name)
+			c.failOnDependencies(job, dependencyFailedReason, msg)
+			return false, nil
+		}
+		if !isSucceeded(dep.Status) {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) > 0 {
+		msg := fmt.Sprintf("MPIJob %s/%s is waiting for MPIJobs %s to succeed.", job.Namespace, job.Name, strings.Join(pending, ", "))
+		if !hasCondition(job.Status, mpiJobWaiting) {
+			c.recorder.Event(job, corev1.EventTypeNormal, dependenciesPendingReason, msg)
+		}
+		setWaitingCondition(job, corev1.ConditionTrue, dependenciesPendingReason, msg)
+		return false, nil
+	}
+	msg := fmt.Sprintf("Dependencies of MPIJob %s/%s succeeded.", job.Namespace, job.Name)
+	setWaitingCondition(job, corev1.ConditionFalse, dependenciesSucceededReason, msg)
+	return true, nil
+}
+
+// failOnDependencies marks the MPIJob as failed because of its dependencies.
+func (c *MPIJobController) failOnDependencies(job *kubeflow.MPIJob, reason, msg string) {
+	setWaitingCondition(job, corev1.ConditionFalse, reason, msg)
+	updateMPIJobConditions(job, common.JobFailed, reason, msg)
+	now := metav1.Now()
+	job.Status.CompletionTime = &now
+	c.recorder.Event(job, corev1.EventTypeWarning, reason, msg)
+	mpiJobsFailureCount.Inc()
+}
+
+// dependencyCycle returns the names of the MPIJobs in a cycle of dependencies
+// that starts and ends with the given MPIJob, or nil if there is none. Only
+// the dependencies that didn't start are followed, as the other ones don't
+// wait for their own dependencies.
+func (c *MPIJobController) dependencyCycle(job *kubeflow.MPIJob) ([]string, error) {
+	visited := sets.NewString()
+	var visit func(path, deps []string) ([]string, error)
+	visit = func(path, deps []string) ([]string, error) {
+		for _, name := range deps {
+			if name == job.Name {
+				return append(path, name), nil
+			}
+			if visited.Has(name) {
+				continue
+			}
+			visited.Insert(name)
+			dep, err := c.mpiJobLister.MPIJobs(job.Namespace).Get(name)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("obtaining dependency %s: %w", name, err)
+			}
+			if dep.Status.StartTime != nil || isFinished(dep.Status) {
+				continue
+			}
+			cycle, err := visit(append(path, name), dep.Spec.DependsOn)
+			if cycle != nil || err != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+	return visit([]string{job.Name}, job.Spec.DependsOn)
+}
+
+// setWaitingCondition sets the Waiting condition of the MPIJob, keeping the
+// message of an existing condition with the same status up to date.
+func setWaitingCondition(job *kubeflow.MPIJob, status corev1.ConditionStatus, reason, message string) {
+	condition := newCondition(mpiJobWaiting, reason, message)
+	condition.Status = status
+	if current := getCondition(job.Status, mpiJobWaiting); current != nil && current.Status == status {
+		condition.LastTransitionTime = current.LastTransitionTime
+		if current.Reason == reason && current.Message == message {
+			return
+		}
+	}
+	job.Status.Conditions = append(filterOutCondition(job.Status.Conditions, mpiJobWaiting), condition)
+}
+
+// enqueueDependents enqueues the unstarted MPIJobs that depend on the given
+// MPIJob, once it finished.
+func (c *MPIJobController) enqueueDependents(obj interface{}) {
+	job, ok := obj.(*kubeflow.MPIJob)
+	if !ok || !isFinished(job.Status) {
+		return
+	}
+	jobs, err := c.mpiJobLister.MPIJobs(job.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list MPIJobs depending on %s/%s: %v", job.Namespace, job.Name, err)
+		return
+	}
+	for _, j := range jobs {
+		if j.Status.StartTime != nil {
+			continue
+		}
+		for _, name := range j.Spec.DependsOn {
+			if name == job.Name {
+				if key, err := cache.MetaNamespaceKeyFunc(j); err == nil {
+					c.queue.Add(key)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+const (
+	// cleanupFinalizer holds the deletion of an MPIJob until the controller
+	// deleted the resources it created for it.
+	cleanupFinalizer = "mpi.kubeflow.org/cleanup"
+
+	cleanupFailedReason = "CleanupFailed"
+)
+
+var (
+	mpiJobCleanupDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mpi_operator_job_cleanup_duration_seconds",
+		Help:    "Time from the deletion of an MPIJob until its resources are cleaned up",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+	mpiJobCleanupErrorsCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mpi_operator_job_cleanup_errors_total",
+		Help: "Counts number of failed attempts to clean up the resources of deleted MPI jobs",
+	})
+	mpiJobsPendingCleanupGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mpi_operator_jobs_pending_cleanup",
+		Help: "Number of deleted MPI jobs whose finalizer is not removed yet",
+	})
+)
+
+// cleanupTracker keeps the keys of the deleted MPIJobs that still have the
+// cleanup finalizer, to export how many are pending.
+type cleanupTracker struct {
+	mu   sync.Mutex
+	keys sets.String
+}
+
+func newCleanupTracker() *cleanupTracker {
+	return &cleanupTracker{keys: sets.NewString()}
+}
+
+func (t *cleanupTracker) start(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys.Insert(key)
+	mpiJobsPendingCleanupGauge.Set(float64(t.keys.Len()))
+}
+
+func (t *cleanupTracker) done(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys.Delete(key)
+	mpiJobsPendingCleanupGauge.Set(float64(t.keys.Len()))
+}
+
+func hasCleanupFinalizer(job *kubeflow.MPIJob) bool {
+	for _, f := range job.Finalizers {
+		if f == cleanupFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// addCleanupFinalizer adds the cleanup finalizer to the stored MPIJob. The
+// caller should stop processing the job: the update triggers a new sync.
+func (c *MPIJobController) addCleanupFinalizer(job *kubeflow.MPIJob) error {
+	// Update the stored object, rather than the one with defaults applied, to
+	// avoid persisting the defaults.
+	shared, err := c.mpiJobLister.MPIJobs(job.Namespace).Get(job.Name)
+	if err != nil {
+		return fmt.Errorf("obtaining job: %w", err)
+	}
+	job = shared.DeepCopy()
+	job.Finalizers = append(job.Finalizers, cleanupFinalizer)
+	if _, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("adding finalizer: %w", err)
+	}
+	return nil
+}
+
+// finalizeMPIJob deletes the resources controlled by a deleted MPIJob and
+// then removes the cleanup finalizer, so that the deletion doesn't depend on
+// the garbage collector.
+func (c *MPIJobController) finalizeMPIJob(job *kubeflow.MPIJob) error {
+	if !hasCleanupFinalizer(job) {
+		return nil
+	}
+	key, err := cache.MetaNamespaceKeyFunc(job)
+	if err != nil {
+		return err
+	}
+	c.cleanups.start(key)
+	if err := c.deleteOwnedResources(job); err != nil {
+		mpiJobCleanupErrorsCount.Inc()
+		c.recorder.Eventf(job, corev1.EventTypeWarning, cleanupFailedReason, "Failed to clean up resources: %v", err)
+		return err
+	}
+
+	var finalizers []string
+	for _, f := range job.Finalizers {
+		if f != cleanupFinalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	job.Finalizers = finalizers
+	if _, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		if errors.IsNotFound(err) {
+			c.cleanups.done(key)
+			return nil
+		}
+		mpiJobCleanupErrorsCount.Inc()
+		return fmt.Errorf("removing finalizer: %w", err)
+	}
+	c.cleanups.done(key)
+	mpiJobCleanupDuration.Observe(time.Since(job.DeletionTimestamp.Time).Seconds())
+	klog.V(4).Infof("Cleaned up resources of MPIJob %s", key)
+	return nil
+}
+
+// deleteOwnedResources deletes the launcher Job, the worker Pods, the
+// Services, the ConfigMap, the SSH Secret and the PodGroup of the MPIJob.
+// Objects with the same names that aren't controlled by the job are left
+// untouched.
+func (c *MPIJobController) deleteOwnedResources(job *kubeflow.MPIJob) error {
+	ns := job.Namespace
+	launcherName := childName(job, launcherSuffix)
+
+	launcherJob, err := c.jobLister.Jobs(ns).Get(launcherName)
+	if err := deleteIfControlled(job, launcherJob, err, c.kubeClient.BatchV1().Jobs(ns).Delete); err != nil {
+		return err
+	}
+	if err := c.deleteControlledWorkers(job); err != nil {
+		return err
+	}
+	for _, name := range []string{childName(job, workerSuffix), launcherName} {
+		svc, err := c.serviceLister.Services(ns).Get(name)
+		if err := deleteIfControlled(job, svc, err, c.kubeClient.CoreV1().Services(ns).Delete); err != nil {
+			return err
+		}
+	}
+	cm, err := c.configMapLister.ConfigMaps(ns).Get(childName(job, configSuffix))
+	if err := deleteIfControlled(job, cm, err, c.kubeClient.CoreV1().ConfigMaps(ns).Delete); err != nil {
+		return err
+	}
+	secret, err := c.secretLister.Secrets(ns).Get(childName(job, sshAuthSecretSuffix))
+	if err := deleteIfControlled(job, secret, err, c.kubeClient.CoreV1().Secrets(ns).Delete); err != nil {
+		return err
+	}
+	if c.podgroupsLister != nil {
+		pg, err := c.podgroupsLister.PodGroups(ns).Get(job.Name)
+		if err := deleteIfControlled(job, pg, err, c.volcanoClient.SchedulingV1beta1().PodGroups(ns).Delete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteControlledWorkers deletes all the worker Pods of the job, regardless
+// of the clean Pod policy.
+func (c *MPIJobController) deleteControlledWorkers(job *kubeflow.MPIJob) error {
+	selector, err := workerSelector(job.Name)
+	if err != nil {
+		return err
+	}
+	workers, err := c.podLister.Pods(job.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, p := range workers {
+		if err := deleteIfControlled(job, p, nil, c.kubeClient.CoreV1().Pods(job.Namespace).Delete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteIfControlled deletes obj, as obtained from a lister along with
+// getErr, if it exists and is controlled by the job. Dependents are deleted
+// in the background.
+func deleteIfControlled(job *kubeflow.MPIJob, obj metav1.Object, getErr error, del func(context.Context, string, metav1.DeleteOptions) error) error {
+	if errors.IsNotFound(getErr) {
+		return nil
+	}
+	if getErr != nil {
+		return getErr
+	}
+	if !metav1.IsControlledBy(obj, job) {
+		return nil
+	}
+	propagation := metav1.DeletePropagationBackground
+	err := del(context.TODO(), obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("deleting %s: %w", obj.GetName(), err)
+	}
+	return nil
+}
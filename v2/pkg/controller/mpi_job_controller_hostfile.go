@@ -0,0 +1,66 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+// hostfileGenerator writes the hostfile in the format of an MPI
+// implementation.
+type hostfileGenerator interface {
+	// hostEntry returns the line of the hostfile for a worker, terminated by
+	// a newline.
+	hostEntry(host string, slots int) string
+}
+
+// openMPIHostfile lists the workers as "<host> slots=<slots>".
+type openMPIHostfile struct{}
+
+func (openMPIHostfile) hostEntry(host string, slots int) string {
+	return fmt.Sprintf("%s slots=%d\n", host, slots)
+}
+
+// intelHostfile lists the workers as "<host>:<slots>", as expected by Hydra.
+type intelHostfile struct{}
+
+func (intelHostfile) hostEntry(host string, slots int) string {
+	return fmt.Sprintf("%s:%d\n", host, slots)
+}
+
+// charmHostfile lists the workers as "host <host> ++cpus <slots>", the
+// nodelist format of charmrun. Hosts outside a group belong to the main group.
+type charmHostfile struct{}
+
+func (charmHostfile) hostEntry(host string, slots int) string {
+	return fmt.Sprintf("host %s ++cpus %d\n", host, slots)
+}
+
+var hostfileGenerators = map[kubeflow.MPIImplementation]hostfileGenerator{
+	kubeflow.MPIImplementationOpenMPI: openMPIHostfile{},
+	kubeflow.MPIImplementationIntel:   intelHostfile{},
+	kubeflow.MPIImplementationCharm:   charmHostfile{},
+}
+
+// hostfileGeneratorFor returns the hostfile generator of the implementation,
+// or the OpenMPI one if it is unknown.
+func hostfileGeneratorFor(impl kubeflow.MPIImplementation) hostfileGenerator {
+	if g, ok := hostfileGenerators[impl]; ok {
+		return g
+	}
+	return openMPIHostfile{}
+}
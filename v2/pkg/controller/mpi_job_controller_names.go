@@ -0,0 +1,62 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+// childNamePrefixAnnotation records the prefix of the names of the resources
+// of an MPIJob whose name is too long to be used as is. The prefix is derived
+// from the name; the annotation lets users find the resources.
+const childNamePrefixAnnotation = "mpi.kubeflow.org/child-name-prefix"
+
+// childName returns the name of the resource of the job with the given
+// suffix.
+func childName(job *kubeflow.MPIJob, suffix string) string {
+	return kubeflow.ChildNamePrefix(job.Name) + suffix
+}
+
+// needsChildNamePrefix returns whether the job name is shortened in the names
+// of its resources and the prefix isn't recorded yet.
+func needsChildNamePrefix(job *kubeflow.MPIJob) bool {
+	prefix := kubeflow.ChildNamePrefix(job.Name)
+	return prefix != job.Name && job.Annotations[childNamePrefixAnnotation] != prefix
+}
+
+// recordChildNamePrefix records the prefix of the names of the resources of
+// the job in its annotations.
+func (c *MPIJobController) recordChildNamePrefix(job *kubeflow.MPIJob) error {
+	// Update the stored object, rather than the one with defaults applied, to
+	// avoid persisting the defaults.
+	shared, err := c.mpiJobLister.MPIJobs(job.Namespace).Get(job.Name)
+	if err != nil {
+		return fmt.Errorf("obtaining job: %w", err)
+	}
+	job = shared.DeepCopy()
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[childNamePrefixAnnotation] = kubeflow.ChildNamePrefix(job.Name)
+	if _, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("recording child name prefix: %w", err)
+	}
+	return nil
+}
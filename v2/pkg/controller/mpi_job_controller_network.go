@@ -0,0 +1,243 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+const (
+	// networksAnnotation is the Multus annotation requesting secondary
+	// networks (NetworkAttachmentDefinitions) for a Pod.
+	networksAnnotation = "k8s.v1.cni.cncf.io/networks"
+	// networkStatusAnnotation is the Multus annotation reporting the
+	// interfaces attached to a Pod.
+	networkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+	// deprecatedNetworkStatusAnnotation is the network status annotation set
+	// by older Multus versions.
+	deprecatedNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/networks-status"
+
+	// workerDNSLookupTimeout is the timeout to resolve a single worker
+	// hostname.
+	workerDNSLookupTimeout = 2 * time.Second
+	// workerDNSRetryInterval is the time to wait before checking again
+	// whether the worker hostnames are resolvable.
+	workerDNSRetryInterval = 5 * time.Second
+
+	// workersNotResolvableReason is the Event reason when the launcher
+	// creation is delayed because worker hostnames don't resolve yet.
+	workersNotResolvableReason = "WorkersNotResolvable"
+	// workersWithoutAddressReason is the Event reason when the launcher
+	// creation is delayed because workers don't report an address in the
+	// host discovery network yet.
+	workersWithoutAddressReason = "WorkersWithoutAddress"
+)
+
+// workerHosts keeps, for each MPIJob, the worker hostnames that resolved and
+// the ones with a lookup in flight. The lookups run in the background; the
+// job is synced again when one succeeds.
+type workerHosts struct {
+	mu       sync.Mutex
+	resolved map[string]sets.String
+	pending  map[string]sets.String
+}
+
+func newWorkerHosts() *workerHosts {
+	return &workerHosts{
+		resolved: make(map[string]sets.String),
+		pending:  make(map[string]sets.String),
+	}
+}
+
+// isResolved returns whether a lookup of the host succeeded.
+func (h *workerHosts) isResolved(key, host string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.resolved[key].Has(host)
+}
+
+// startLookup marks a lookup of the host as in flight. It returns false if
+// there was one already.
+func (h *workerHosts) startLookup(key, host string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pending[key].Has(host) {
+		return false
+	}
+	if h.pending[key] == nil {
+		h.pending[key] = sets.NewString()
+	}
+	h.pending[key].Insert(host)
+	return true
+}
+
+// finishLookup records the result of a lookup of the host.
+func (h *workerHosts) finishLookup(key, host string, resolved bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.pending[key].Has(host) {
+		// The job was released while the lookup was in flight.
+		return
+	}
+	h.pending[key].Delete(host)
+	if !resolved {
+		return
+	}
+	if h.resolved[key] == nil {
+		h.resolved[key] = sets.NewString()
+	}
+	h.resolved[key].Insert(host)
+}
+
+// release forgets the hosts of the job.
+func (h *workerHosts) release(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.resolved, key)
+	delete(h.pending, key)
+}
+
+// networkStatus is an entry of the Multus network-status annotation.
+type networkStatus struct {
+	Name string   `json:"name"`
+	IPs  []string `json:"ips,omitempty"`
+}
+
+// setNetworksAnnotation propagates the secondary networks requested in the
+// MPIJob annotations to a Pod template that doesn't request its own.
+func setNetworksAnnotation(podTemplate *corev1.PodTemplateSpec, job *kubeflow.MPIJob) {
+	networks, ok := job.Annotations[networksAnnotation]
+	if !ok {
+		return
+	}
+	if _, ok := podTemplate.Annotations[networksAnnotation]; ok {
+		return
+	}
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = map[string]string{}
+	}
+	podTemplate.Annotations[networksAnnotation] = networks
+}
+
+// workerAddresses returns the addresses of the workers in the job's host
+// discovery network, keyed by Pod name. Workers that don't report an address
+// in the network are omitted.
+func workerAddresses(job *kubeflow.MPIJob, pods []*corev1.Pod) map[string]string {
+	network := job.Spec.HostDiscoveryNetwork
+	if network == "" {
+		return nil
+	}
+	addresses := make(map[string]string, len(pods))
+	for _, p := range pods {
+		if addr := networkAddress(p, network); addr != "" {
+			addresses[p.Name] = addr
+		}
+	}
+	return addresses
+}
+
+// networkAddress returns the first IP of the Pod in the given network. The
+// network can be given with or without the namespace of its
+// NetworkAttachmentDefinition.
+func networkAddress(pod *corev1.Pod, network string) string {
+	raw, ok := pod.Annotations[networkStatusAnnotation]
+	if !ok {
+		raw, ok = pod.Annotations[deprecatedNetworkStatusAnnotation]
+	}
+	if !ok {
+		return ""
+	}
+	var statuses []networkStatus
+	if err := json.Unmarshal([]byte(raw), &statuses); err != nil {
+		klog.Warningf("Failed to parse network status of Pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return ""
+	}
+	qualified := network
+	if !strings.Contains(network, "/") {
+		qualified = pod.Namespace + "/" + network
+	}
+	for _, s := range statuses {
+		if (s.Name == network || s.Name == qualified) && len(s.IPs) > 0 {
+			return s.IPs[0]
+		}
+	}
+	return ""
+}
+
+// workerWithoutAddress returns the name of the first worker that doesn't
+// report an address in the job's host discovery network, or an empty string
+// if all of them do or the job doesn't set a host discovery network.
+func workerWithoutAddress(job *kubeflow.MPIJob, workers []*corev1.Pod) string {
+	if job.Spec.HostDiscoveryNetwork == "" {
+		return ""
+	}
+	addresses := workerAddresses(job, workers)
+	for _, p := range workers {
+		if _, ok := addresses[p.Name]; !ok {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// unresolvableWorker returns the FQDN of the first worker that the controller
+// didn't resolve yet, or an empty string if all the workers listed by hostname
+// in the hostfile are resolvable. It starts the missing lookups without
+// waiting for them.
+func (c *MPIJobController) unresolvableWorker(key string, job *kubeflow.MPIJob, workers []*corev1.Pod) string {
+	addresses := workerAddresses(job, workers)
+	workersService := childName(job, workerSuffix)
+	var unresolvable string
+	for _, p := range workers {
+		if _, ok := addresses[p.Name]; ok {
+			continue
+		}
+		fqdn := fmt.Sprintf("%s.%s.%s.svc", p.Name, workersService, p.Namespace)
+		if c.workerHosts.isResolved(key, fqdn) {
+			continue
+		}
+		if c.workerHosts.startLookup(key, fqdn) {
+			go c.lookupWorker(key, fqdn)
+		}
+		if unresolvable == "" {
+			unresolvable = fqdn
+		}
+	}
+	return unresolvable
+}
+
+// lookupWorker resolves the worker hostname and syncs the job if it succeeds.
+func (c *MPIJobController) lookupWorker(key, fqdn string) {
+	ctx, cancel := context.WithTimeout(context.Background(), workerDNSLookupTimeout)
+	defer cancel()
+	_, err := c.lookupHost(ctx, fqdn)
+	c.workerHosts.finishLookup(key, fqdn, err == nil)
+	if err != nil {
+		klog.V(4).Infof("Worker %s is not resolvable yet: %v", fqdn, err)
+		return
+	}
+	c.queue.Add(key)
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+const (
+	// sshPortAnnotation records the SSH port allocated to an MPIJob whose
+	// workers run in the host network namespace.
+	sshPortAnnotation = "mpi.kubeflow.org/ssh-port"
+	// sshPortEnv exposes the allocated SSH port to the launcher and workers,
+	// so that custom entrypoints can start sshd on it.
+	sshPortEnv = "K_MPI_SSH_PORT"
+
+	sshPortAllocatedReason = "SSHPortAllocated"
+)
+
+// portAllocator hands out host ports to MPIJobs from a fixed range. The
+// allocations are persisted in the MPIJob annotations; reservations only
+// cover the window until the informer cache observes the annotation.
+type portAllocator struct {
+	mu           sync.Mutex
+	reservations map[string]int32
+}
+
+func newPortAllocator() *portAllocator {
+	return &portAllocator{reservations: make(map[string]int32)}
+}
+
+// release drops the reservation of the job, if any.
+func (a *portAllocator) release(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.reservations, key)
+}
+
+// needsSSHPort returns whether an SSH port must be allocated for the job.
+func needsSSHPort(job *kubeflow.MPIJob) bool {
+	w := job.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeWorker]
+	if w == nil || !w.Template.Spec.HostNetwork {
+		return false
+	}
+	_, ok := sshPortFromAnnotations(job)
+	return !ok
+}
+
+// sshPortFromAnnotations returns the SSH port allocated to the job, if any.
+func sshPortFromAnnotations(job *kubeflow.MPIJob) (int32, bool) {
+	v, ok := job.Annotations[sshPortAnnotation]
+	if !ok {
+		return 0, false
+	}
+	port, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || port <= 0 {
+		return 0, false
+	}
+	return int32(port), true
+}
+
+// allocateSSHPort picks a free port for the job and records it in the job
+// annotations. The caller should stop processing the job after a successful
+// allocation: the update triggers a new sync with the annotated object.
+func (c *MPIJobController) allocateSSHPort(job *kubeflow.MPIJob) error {
+	key, err := cache.MetaNamespaceKeyFunc(job)
+	if err != nil {
+		return err
+	}
+	jobs, err := c.mpiJobLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("listing MPIJobs: %w", err)
+	}
+
+	c.ports.mu.Lock()
+	defer c.ports.mu.Unlock()
+	used := sets.NewInt32()
+	observed := sets.NewString()
+	for _, j := range jobs {
+		k, err := cache.MetaNamespaceKeyFunc(j)
+		if err != nil {
+			continue
+		}
+		observed.Insert(k)
+		if port, ok := sshPortFromAnnotations(j); ok {
+			// Drop the reservation once the informer caught up.
+			delete(c.ports.reservations, k)
+			// Workers of finished jobs can remain, depending on the
+			// cleanPodPolicy, and keep listening on the port.
+			inUse := !isFinished(j.Status)
+			if !inUse {
+				if inUse, err = c.hasWorkerPods(j); err != nil {
+					return err
+				}
+			}
+			if inUse {
+				used.Insert(port)
+			}
+		}
+	}
+	for k, port := range c.ports.reservations {
+		if k != key && !observed.Has(k) {
+			delete(c.ports.reservations, k)
+			continue
+		}
+		if k != key {
+			used.Insert(port)
+		}
+	}
+	port, ok := c.ports.reservations[key]
+	if !ok {
+		port, ok = nextFreePort(c.hostNetworkPorts, used)
+		if !ok {
+			msg := fmt.Sprintf("No free host port in range %s for SSH", c.hostNetworkPorts.String())
+			c.recorder.Event(job, corev1.EventTypeWarning, mpiJobFailedReason, msg)
+			return fmt.Errorf("%s", msg)
+		}
+	}
+
+	// Record the allocation on the stored object, rather than the one with
+	// defaults applied, to avoid persisting the defaults.
+	shared, err := c.mpiJobLister.MPIJobs(job.Namespace).Get(job.Name)
+	if err != nil {
+		return fmt.Errorf("obtaining job: %w", err)
+	}
+	job = shared.DeepCopy()
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[sshPortAnnotation] = strconv.Itoa(int(port))
+	if _, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("recording allocated SSH port: %w", err)
+	}
+	c.ports.reservations[key] = port
+	klog.V(4).Infof("Allocated SSH port %d to MPIJob %s", port, key)
+	c.recorder.Eventf(job, corev1.EventTypeNormal, sshPortAllocatedReason, "Allocated host port %d for SSH", port)
+	return nil
+}
+
+// hasWorkerPods returns whether any worker pod of the job still exists.
+func (c *MPIJobController) hasWorkerPods(job *kubeflow.MPIJob) (bool, error) {
+	selector, err := workerSelector(job.Name)
+	if err != nil {
+		return false, err
+	}
+	pods, err := c.podLister.Pods(job.Namespace).List(selector)
+	if err != nil {
+		return false, fmt.Errorf("listing worker pods: %w", err)
+	}
+	return len(pods) > 0, nil
+}
+
+// nextFreePort returns the lowest port in the range that is not in use.
+func nextFreePort(pr utilnet.PortRange, used sets.Int32) (int32, bool) {
+	for p := pr.Base; p < pr.Base+pr.Size; p++ {
+		if !used.Has(int32(p)) {
+			return int32(p), true
+		}
+	}
+	return 0, false
+}
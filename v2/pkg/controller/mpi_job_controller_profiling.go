@@ -0,0 +1,83 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+const (
+	// profileDirEnv exposes the directory where the profiler output claim is
+	// mounted.
+	profileDirEnv = "K_MPI_PROFILE_DIR"
+	// openMPIEnvListEnv lists the environment variables that mpirun forwards
+	// to the processes it starts.
+	openMPIEnvListEnv = "OMPI_MCA_mca_base_env_list"
+
+	profileOutputVolume = "profile-output"
+)
+
+// setupProfiling adds the profiler environment and output volume of the job
+// to the main container of a launcher or worker Pod.
+func setupProfiling(podSpec *corev1.PodSpec, job *kubeflow.MPIJob, isLauncher bool) {
+	p := job.Spec.Profiling
+	if p == nil {
+		return
+	}
+	container := &podSpec.Containers[0]
+	names := make([]string, 0, len(p.Env)+1)
+	for name := range p.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: p.Env[name]})
+	}
+
+	if p.OutputClaimName != "" {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: profileOutputVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: p.OutputClaimName,
+				},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      profileOutputVolume,
+			MountPath: p.OutputPath,
+		})
+		container.Env = append(container.Env, corev1.EnvVar{Name: profileDirEnv, Value: p.OutputPath})
+		names = append(names, profileDirEnv)
+	}
+
+	// The processes started through SSH don't inherit the environment of the
+	// worker containers. Intel MPI forwards the whole launcher environment,
+	// but OpenMPI only forwards the variables listed in its MCA parameter.
+	if isLauncher && job.Spec.MPIImplementation == kubeflow.MPIImplementationOpenMPI && len(names) > 0 {
+		for i := range container.Env {
+			if env := &container.Env[i]; env.Name == openMPIEnvListEnv && env.Value != "" {
+				env.Value = strings.Join(append([]string{env.Value}, names...), ";")
+				return
+			}
+		}
+		container.Env = append(container.Env, corev1.EnvVar{Name: openMPIEnvListEnv, Value: strings.Join(names, ";")})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	common "github.com/kubeflow/common/pkg/apis/common/v1"
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+const (
+	// requeueCountAnnotation records how many times an MPIJob was requeued.
+	// The launcher Job carries the value of the attempt it belongs to.
+	requeueCountAnnotation = "mpi.kubeflow.org/requeue-count"
+	// requeueAfterAnnotation records, in RFC 3339 format, when the backoff
+	// of a pending requeue ends. It is removed once the launcher and the
+	// workers of the failed attempt are deleted.
+	requeueAfterAnnotation = "mpi.kubeflow.org/requeue-after"
+
+	// mpiJobRequeuedReason is added in a mpijob when it is retried after
+	// its launcher failed.
+	mpiJobRequeuedReason = "MPIJobRequeued"
+)
+
+var mpiJobsRequeuedCount = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "mpi_operator_jobs_requeued_total",
+	Help: "Counts number of MPI jobs requeued after their launcher failed",
+})
+
+// requeueCount returns the number of times the job was requeued.
+func requeueCount(job *kubeflow.MPIJob) int32 {
+	v, err := strconv.ParseInt(job.Annotations[requeueCountAnnotation], 10, 32)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return int32(v)
+}
+
+// launcherAnnotations returns the annotations that tie a launcher Job to
+// the current attempt of the MPIJob.
+func launcherAnnotations(job *kubeflow.MPIJob) map[string]string {
+	v, ok := job.Annotations[requeueCountAnnotation]
+	if !ok {
+		return nil
+	}
+	return map[string]string{requeueCountAnnotation: v}
+}
+
+// isCurrentAttempt returns whether the launcher belongs to the current
+// attempt of the job, rather than to one that was requeued.
+func isCurrentAttempt(job *kubeflow.MPIJob, launcher *batchv1.Job) bool {
+	return launcher.Annotations[requeueCountAnnotation] == job.Annotations[requeueCountAnnotation]
+}
+
+// canRequeue returns whether the job must be retried because its launcher
+// reached the backoff limit.
+func canRequeue(job *kubeflow.MPIJob, launcher *batchv1.Job) bool {
+	p := job.Spec.RequeueOnFailure
+	if p == nil || requeueCount(job) >= p.MaxRequeues || !isJobFailed(launcher) {
+		return false
+	}
+	return getJobCondition(launcher, batchv1.JobFailed).Reason == jobBackoffLimitExceededReason
+}
+
+// requeueAfter returns when the backoff of the pending requeue of the job
+// ends. ok is false if there is no pending requeue.
+func requeueAfter(job *kubeflow.MPIJob) (t time.Time, ok bool) {
+	v, ok := job.Annotations[requeueAfterAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		// Retry right away rather than getting stuck.
+		return time.Time{}, true
+	}
+	return t, true
+}
+
+// recordRequeue records the new attempt of the job and when its backoff
+// ends, so that the requeue proceeds even if the failed launcher is gone.
+// The update syncs the job again.
+func (c *MPIJobController) recordRequeue(mpiJob *kubeflow.MPIJob, launcher *batchv1.Job) error {
+	failedAt := getJobCondition(launcher, batchv1.JobFailed).LastTransitionTime
+	backoff := time.Duration(*mpiJob.Spec.RequeueOnFailure.BackoffSeconds) * time.Second
+	err := c.updateRequeueAnnotations(mpiJob, func(annotations map[string]string) {
+		annotations[requeueCountAnnotation] = strconv.Itoa(int(requeueCount(mpiJob) + 1))
+		annotations[requeueAfterAnnotation] = failedAt.Add(backoff).UTC().Format(time.RFC3339)
+	})
+	if err != nil {
+		return err
+	}
+	mpiJobsRequeuedCount.Inc()
+	return nil
+}
+
+// requeueMPIJob marks the job as restarting and, once the backoff elapsed,
+// deletes the failed launcher and the workers of the job. The next sync
+// creates the workers and launcher again.
+func (c *MPIJobController) requeueMPIJob(mpiJob *kubeflow.MPIJob, launcher *batchv1.Job, after time.Time) error {
+	oldStatus := mpiJob.Status.DeepCopy()
+	msg := fmt.Sprintf("Launcher %s exceeded its backoff limit, requeueing MPIJob %s/%s", childName(mpiJob, launcherSuffix), mpiJob.Namespace, mpiJob.Name)
+	if p := mpiJob.Spec.RequeueOnFailure; p != nil {
+		msg += fmt.Sprintf(" (%d/%d)", requeueCount(mpiJob), p.MaxRequeues)
+	}
+	updateMPIJobConditions(mpiJob, common.JobRestarting, mpiJobRequeuedReason, msg)
+	if !reflect.DeepEqual(*oldStatus, mpiJob.Status) {
+		c.recorder.Event(mpiJob, corev1.EventTypeWarning, mpiJobRequeuedReason, msg)
+		// The job is synced again once the status is observed.
+		return c.updateStatusHandler(mpiJob)
+	}
+
+	if wait := time.Until(after); wait > 0 {
+		key, err := cache.MetaNamespaceKeyFunc(mpiJob)
+		if err != nil {
+			return err
+		}
+		c.queue.AddAfter(key, wait)
+		return nil
+	}
+
+	// Delete before clearing the backoff, so that a failed deletion is
+	// retried.
+	if launcher != nil {
+		if err := deleteIfControlled(mpiJob, launcher, nil, c.kubeClient.BatchV1().Jobs(launcher.Namespace).Delete); err != nil {
+			return err
+		}
+	}
+	if err := c.deleteControlledWorkers(mpiJob); err != nil {
+		return err
+	}
+	return c.updateRequeueAnnotations(mpiJob, func(annotations map[string]string) {
+		delete(annotations, requeueAfterAnnotation)
+	})
+}
+
+// updateRequeueAnnotations applies the change to the annotations of the
+// job.
+func (c *MPIJobController) updateRequeueAnnotations(mpiJob *kubeflow.MPIJob, change func(map[string]string)) error {
+	// Update the stored object, rather than the one with defaults applied,
+	// to avoid persisting the defaults.
+	shared, err := c.mpiJobLister.MPIJobs(mpiJob.Namespace).Get(mpiJob.Name)
+	if err != nil {
+		return fmt.Errorf("obtaining job: %w", err)
+	}
+	job := shared.DeepCopy()
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	change(job.Annotations)
+	if _, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("recording requeue: %w", err)
+	}
+	return nil
+}
+
+// hasTerminatingWorkers returns whether any worker of the job is being
+// deleted, as it happens after the job is requeued.
+func (c *MPIJobController) hasTerminatingWorkers(job *kubeflow.MPIJob) (bool, error) {
+	selector, err := workerSelector(job.Name)
+	if err != nil {
+		return false, err
+	}
+	pods, err := c.podLister.Pods(job.Namespace).List(selector)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range pods {
+		if p.DeletionTimestamp != nil && metav1.IsControlledBy(p, job) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+// workerSizes keeps the number of workers of each MPIJob as of its last
+// successful sync. Comparing it with the replicas tells a change of the job
+// size apart from workers that are recreated after being deleted or evicted,
+// or that are created again after a failed sync.
+type workerSizes struct {
+	mu    sync.Mutex
+	sizes map[string]int32
+}
+
+func newWorkerSizes() *workerSizes {
+	return &workerSizes{sizes: make(map[string]int32)}
+}
+
+// get returns the size observed in the last successful sync of the job.
+func (s *workerSizes) get(key string) (int32, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	size, ok := s.sizes[key]
+	return size, ok
+}
+
+func (s *workerSizes) set(key string, size int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sizes[key] = size
+}
+
+// release forgets the size of the job, if any.
+func (s *workerSizes) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sizes, key)
+}
+
+// liveWorkers returns the worker pods that are not being deleted.
+func liveWorkers(pods []*corev1.Pod) []*corev1.Pod {
+	var live []*corev1.Pod
+	for _, p := range pods {
+		if p.DeletionTimestamp == nil {
+			live = append(live, p)
+		}
+	}
+	return live
+}
+
+// previousWorkerSize returns the number of workers the job had before this
+// sync. It's unknown for the first sync of a job after the controller starts,
+// unless there are more live workers than replicas, which only happens after
+// the replicas were lowered.
+func (c *MPIJobController) previousWorkerSize(key string, live int, replicas int32) (int32, bool) {
+	if size, ok := c.workerSizes.get(key); ok {
+		return size, true
+	}
+	if live > int(replicas) {
+		return int32(live), true
+	}
+	return 0, false
+}
+
+// recordWorkerSize emits an event if the job was expanded or shrunk since
+// its previous sync, and remembers the current size.
+func (c *MPIJobController) recordWorkerSize(job *kubeflow.MPIJob, key string, prev int32, known bool, replicas int32) {
+	c.workerSizes.set(key, replicas)
+	if !known || prev == replicas {
+		return
+	}
+	if prev < replicas {
+		c.recorder.Eventf(job, corev1.EventTypeNormal, mpiJobExpandedReason, "MPIJob %s/%s expanded from %d to %d workers", job.Namespace, job.Name, prev, replicas)
+	} else {
+		c.recorder.Eventf(job, corev1.EventTypeNormal, mpiJobShrunkReason, "MPIJob %s/%s shrunk from %d to %d workers", job.Namespace, job.Name, prev, replicas)
+	}
+}
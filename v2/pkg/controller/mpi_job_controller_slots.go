@@ -0,0 +1,49 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+)
+
+// setSlotsFromResource sets the slots per worker of a job that doesn't
+// specify them to the amount of the given resource, such as nvidia.com/gpu,
+// requested by the containers of a worker. The job is left unchanged if the
+// workers don't request the resource, so that the default applies.
+func setSlotsFromResource(job *kubeflow.MPIJob, resource corev1.ResourceName) {
+	if job.Spec.SlotsPerWorker != nil {
+		return
+	}
+	worker := job.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeWorker]
+	if worker == nil {
+		return
+	}
+	var slots int64
+	for _, c := range worker.Template.Spec.Containers {
+		// Extended resources can only be set in limits, in which case the
+		// request defaults to the limit.
+		q, ok := c.Resources.Requests[resource]
+		if !ok {
+			q = c.Resources.Limits[resource]
+		}
+		slots += q.Value()
+	}
+	if slots > 0 {
+		s := int32(slots)
+		job.Spec.SlotsPerWorker = &s
+	}
+}
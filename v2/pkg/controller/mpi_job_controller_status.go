@@ -33,6 +33,10 @@ const (
 	mpiJobFailedReason = "MPIJobFailed"
 	// mpiJobEvict
 	mpiJobEvict = "MPIJobEvicted"
+	// mpiJobExpandedReason is added in a mpijob when workers are added to it.
+	mpiJobExpandedReason = "MPIJobExpanded"
+	// mpiJobShrunkReason is added in a mpijob when workers are removed from it.
+	mpiJobShrunkReason = "MPIJobShrunk"
 )
 
 // initializeMPIJobStatuses initializes the ReplicaStatuses for MPIJob.
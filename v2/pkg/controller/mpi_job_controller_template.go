@@ -0,0 +1,281 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	common "github.com/kubeflow/common/pkg/apis/common/v1"
+	kubeflow "github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/v2beta1"
+	"github.com/kubeflow/mpi-operator/v2/pkg/apis/kubeflow/validation"
+	"github.com/kubeflow/mpi-operator/v2/pkg/client/clientset/versioned/scheme"
+)
+
+const (
+	// templateSpecAnnotation records the spec of the MPIJobTemplate that an
+	// MPIJob is based on, so that changes to the template, or its deletion,
+	// don't affect the MPIJob once it started.
+	templateSpecAnnotation = "mpi.kubeflow.org/template-spec"
+
+	templateNotFoundReason = "TemplateNotFound"
+)
+
+// applyTemplate replaces the spec of the MPIJob with the spec of its
+// MPIJobTemplate, overridden by the fields set in the MPIJob. Until the job
+// starts, the current spec of the template is recorded in the job; afterwards,
+// the recorded spec is used. It returns false if there is no spec to use or it
+// can't be merged, or if the spec was just recorded, in which case the job is
+// synced again once the record is observed.
+func (c *MPIJobController) applyTemplate(job *kubeflow.MPIJob) (bool, error) {
+	recorded, hasRecorded := job.Annotations[templateSpecAnnotation]
+	if !hasRecorded || job.Status.StartTime == nil {
+		current, err := c.currentTemplateSpec(job)
+		if err != nil {
+			return false, err
+		}
+		if current == "" && !hasRecorded {
+			return false, nil
+		}
+		if current != "" && current != recorded {
+			return false, c.recordTemplateSpec(job, current)
+		}
+	}
+	var templateSpec kubeflow.MPIJobSpec
+	if err := json.Unmarshal([]byte(recorded), &templateSpec); err != nil {
+		c.recorder.Eventf(job, corev1.EventTypeWarning, mpiJobFailedReason, "Failed to parse the spec of MPIJobTemplate %s recorded in %s: %v", job.Spec.TemplateName, templateSpecAnnotation, err)
+		return false, nil
+	}
+	spec, err := mergeMPIJobSpec(&templateSpec, &job.Spec)
+	if err != nil {
+		c.recorder.Eventf(job, corev1.EventTypeWarning, mpiJobFailedReason, "Failed to apply MPIJobTemplate %s: %v", job.Spec.TemplateName, err)
+		return false, nil
+	}
+	job.Spec = spec
+	return true, nil
+}
+
+// currentTemplateSpec returns the spec of the MPIJobTemplate of the job
+// serialized as JSON, or an empty string if the template doesn't exist.
+func (c *MPIJobController) currentTemplateSpec(job *kubeflow.MPIJob) (string, error) {
+	if c.mpiJobTemplateLister == nil {
+		c.recorder.Eventf(job, corev1.EventTypeWarning, templateNotFoundReason, "MPIJobTemplates are not enabled in the operator")
+		return "", nil
+	}
+	template, err := c.mpiJobTemplateLister.MPIJobTemplates(job.Namespace).Get(job.Spec.TemplateName)
+	if errors.IsNotFound(err) {
+		c.recorder.Eventf(job, corev1.EventTypeWarning, templateNotFoundReason, "MPIJobTemplate %s not found", job.Spec.TemplateName)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("obtaining MPIJobTemplate: %w", err)
+	}
+	data, err := json.Marshal(template.Spec)
+	if err != nil {
+		return "", fmt.Errorf("serializing MPIJobTemplate spec: %w", err)
+	}
+	return string(data), nil
+}
+
+// recordTemplateSpec records the spec of the MPIJobTemplate in the job
+// annotations.
+func (c *MPIJobController) recordTemplateSpec(job *kubeflow.MPIJob, spec string) error {
+	// Update the stored object, rather than the one with defaults applied, to
+	// avoid persisting the defaults.
+	shared, err := c.mpiJobLister.MPIJobs(job.Namespace).Get(job.Name)
+	if err != nil {
+		return fmt.Errorf("obtaining job: %w", err)
+	}
+	job = shared.DeepCopy()
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[templateSpecAnnotation] = spec
+	if _, err := c.kubeflowClient.KubeflowV2beta1().MPIJobs(job.Namespace).Update(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("recording MPIJobTemplate spec: %w", err)
+	}
+	return nil
+}
+
+// enqueueTemplateUsers enqueues the MPIJobs that use the MPIJobTemplate.
+func (c *MPIJobController) enqueueTemplateUsers(obj interface{}) {
+	template, ok := obj.(*kubeflow.MPIJobTemplate)
+	if !ok {
+		return
+	}
+	jobs, err := c.mpiJobLister.MPIJobs(template.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list MPIJobs using template %s/%s: %v", template.Namespace, template.Name, err)
+		return
+	}
+	for _, j := range jobs {
+		if j.Spec.TemplateName != template.Name {
+			continue
+		}
+		if key, err := cache.MetaNamespaceKeyFunc(j); err == nil {
+			c.queue.Add(key)
+		}
+	}
+}
+
+// validateNewMPIJob validates an MPIJob before creating it. MPIJobs based on
+// a template are validated after merging the template, when they are synced.
+func validateNewMPIJob(job *kubeflow.MPIJob) field.ErrorList {
+	if job.Spec.TemplateName != "" {
+		return nil
+	}
+	defaulted := job.DeepCopy()
+	scheme.Scheme.Default(defaulted)
+	return validation.ValidateMPIJob(defaulted)
+}
+
+// mergeMPIJobSpec returns the template spec overridden by the fields set in
+// overrides, as documented in the MPIJobTemplate type.
+func mergeMPIJobSpec(template, overrides *kubeflow.MPIJobSpec) (kubeflow.MPIJobSpec, error) {
+	spec := template.DeepCopy()
+	o := overrides.DeepCopy()
+	if o.SlotsPerWorker != nil {
+		spec.SlotsPerWorker = o.SlotsPerWorker
+	}
+	if o.RunPolicy.CleanPodPolicy != nil {
+		spec.RunPolicy.CleanPodPolicy = o.RunPolicy.CleanPodPolicy
+	}
+	if o.RunPolicy.TTLSecondsAfterFinished != nil {
+		spec.RunPolicy.TTLSecondsAfterFinished = o.RunPolicy.TTLSecondsAfterFinished
+	}
+	if o.RunPolicy.ActiveDeadlineSeconds != nil {
+		spec.RunPolicy.ActiveDeadlineSeconds = o.RunPolicy.ActiveDeadlineSeconds
+	}
+	if o.RunPolicy.BackoffLimit != nil {
+		spec.RunPolicy.BackoffLimit = o.RunPolicy.BackoffLimit
+	}
+	if o.RunPolicy.SchedulingPolicy != nil {
+		spec.RunPolicy.SchedulingPolicy = o.RunPolicy.SchedulingPolicy
+	}
+	if o.RequeueOnFailure != nil {
+		spec.RequeueOnFailure = o.RequeueOnFailure
+	}
+	if o.DeadlineSeconds != nil {
+		spec.DeadlineSeconds = o.DeadlineSeconds
+	}
+	if spec.MPIReplicaSpecs == nil && len(o.MPIReplicaSpecs) > 0 {
+		spec.MPIReplicaSpecs = make(map[kubeflow.MPIReplicaType]*common.ReplicaSpec)
+	}
+	for rType, rs := range o.MPIReplicaSpecs {
+		if rs == nil {
+			continue
+		}
+		base := spec.MPIReplicaSpecs[rType]
+		if base == nil {
+			spec.MPIReplicaSpecs[rType] = rs
+			continue
+		}
+		if rs.Replicas != nil {
+			base.Replicas = rs.Replicas
+		}
+		if rs.RestartPolicy != "" {
+			base.RestartPolicy = rs.RestartPolicy
+		}
+		if err := mergePodTemplate(&base.Template, &rs.Template); err != nil {
+			return kubeflow.MPIJobSpec{}, fmt.Errorf("merging %s pod template: %w", rType, err)
+		}
+	}
+	spec.DependsOn = o.DependsOn
+	spec.TemplateName = o.TemplateName
+	if o.SSHAuthMountPath != "" {
+		spec.SSHAuthMountPath = o.SSHAuthMountPath
+	}
+	if o.SSHOptions != nil {
+		spec.SSHOptions = o.SSHOptions
+	}
+	if o.MPIImplementation != "" {
+		spec.MPIImplementation = o.MPIImplementation
+	}
+	if o.HostDiscoveryNetwork != "" {
+		spec.HostDiscoveryNetwork = o.HostDiscoveryNetwork
+	}
+	if o.MetadataPolicy != nil {
+		spec.MetadataPolicy = o.MetadataPolicy
+	}
+	if o.Profiling != nil {
+		spec.Profiling = o.Profiling
+	}
+	return *spec, nil
+}
+
+// mergePodTemplate merges the pod template of the MPIJob into the one of the
+// MPIJobTemplate as a strategic merge patch, like kubectl apply does. The
+// fields set in the MPIJob win, maps are merged by key and lists with a merge
+// key, such as containers, volumes and env vars, are merged item by item.
+func mergePodTemplate(base, overrides *corev1.PodTemplateSpec) error {
+	original, err := json.Marshal(base)
+	if err != nil {
+		return err
+	}
+	patch, err := podTemplatePatch(overrides)
+	if err != nil {
+		return err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, corev1.PodTemplateSpec{})
+	if err != nil {
+		return err
+	}
+	var result corev1.PodTemplateSpec
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return err
+	}
+	*base = result
+	return nil
+}
+
+// podTemplatePatch returns the pod template as a patch. Null values are
+// removed, as they would delete the fields of the template rather than
+// leaving them unset.
+func podTemplatePatch(template *corev1.PodTemplateSpec) ([]byte, error) {
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return nil, err
+	}
+	removeNulls(data)
+	return json.Marshal(data)
+}
+
+func removeNulls(obj map[string]interface{}) {
+	for k, v := range obj {
+		switch v := v.(type) {
+		case nil:
+			delete(obj, k)
+		case map[string]interface{}:
+			removeNulls(v)
+		case []interface{}:
+			for _, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					removeNulls(m)
+				}
+			}
+		}
+	}
+}
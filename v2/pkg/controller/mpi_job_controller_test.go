@@ -15,6 +15,9 @@
 package controller
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -24,16 +27,21 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	kubeinformers "k8s.io/client-go/informers"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 
 	podgroupv1beta1 "volcano.sh/apis/pkg/apis/scheduling/v1beta1"
 	volcanofake "volcano.sh/apis/pkg/client/clientset/versioned/fake"
@@ -69,6 +77,7 @@ type fixture struct {
 	jobLister       []*batchv1.Job
 	podLister       []*corev1.Pod
 	mpiJobLister    []*kubeflow.MPIJob
+	templateLister  []*kubeflow.MPIJobTemplate
 
 	// Actions expected to happen on the client.
 	kubeActions []core.Action
@@ -77,6 +86,10 @@ type fixture struct {
 	// Objects from here are pre-loaded into NewSimpleFake.
 	kubeObjects []runtime.Object
 	objects     []runtime.Object
+
+	hostNetworkPorts utilnet.PortRange
+	lookupHost       func(ctx context.Context, host string) ([]string, error)
+	ownedObjectsOnly bool
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -92,8 +105,9 @@ func newMPIJobCommon(name string, startTime, completionTime *metav1.Time) *kubef
 	mpiJob := &kubeflow.MPIJob{
 		TypeMeta: metav1.TypeMeta{APIVersion: kubeflow.SchemeGroupVersion.String()},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: metav1.NamespaceDefault,
+			Name:       name,
+			Namespace:  metav1.NamespaceDefault,
+			Finalizers: []string{cleanupFinalizer},
 		},
 		Spec: kubeflow.MPIJobSpec{
 			RunPolicy: common.RunPolicy{
@@ -150,7 +164,11 @@ func (f *fixture) newController(gangSchedulerName string) (*MPIJobController, in
 	f.kubeClient = k8sfake.NewSimpleClientset(f.kubeObjects...)
 
 	i := informers.NewSharedInformerFactory(f.client, noResyncPeriodFunc())
-	k8sI := kubeinformers.NewSharedInformerFactory(f.kubeClient, noResyncPeriodFunc())
+	var k8sOptions []kubeinformers.SharedInformerOption
+	if f.ownedObjectsOnly {
+		k8sOptions = append(k8sOptions, kubeinformers.WithTweakListOptions(SelectOwnedObjects))
+	}
+	k8sI := kubeinformers.NewSharedInformerFactoryWithOptions(f.kubeClient, noResyncPeriodFunc(), k8sOptions...)
 
 	volcanoInformerFactory := volcanoinformers.NewSharedInformerFactory(f.volcanoClient, 0)
 	podgroupsInformer := volcanoInformerFactory.Scheduling().V1beta1().PodGroups()
@@ -166,8 +184,18 @@ func (f *fixture) newController(gangSchedulerName string) (*MPIJobController, in
 		k8sI.Core().V1().Pods(),
 		podgroupsInformer,
 		i.Kubeflow().V2beta1().MPIJobs(),
+		i.Kubeflow().V2beta1().MPIJobTemplates(),
+		nil,
 		gangSchedulerName,
+		f.hostNetworkPorts,
+		false,
+		nil,
+		"",
+		record.CorrelatorOptions{},
 	)
+	if f.lookupHost != nil {
+		c.lookupHost = f.lookupHost
+	}
 
 	c.configMapSynced = alwaysReady
 	c.serviceSynced = alwaysReady
@@ -175,6 +203,7 @@ func (f *fixture) newController(gangSchedulerName string) (*MPIJobController, in
 	c.podSynced = alwaysReady
 	c.podgroupsSynced = alwaysReady
 	c.mpiJobSynced = alwaysReady
+	c.mpiJobTemplateSynced = alwaysReady
 	c.recorder = &record.FakeRecorder{}
 
 	for _, configMap := range f.configMapLister {
@@ -226,6 +255,13 @@ func (f *fixture) newController(gangSchedulerName string) (*MPIJobController, in
 		}
 	}
 
+	for _, template := range f.templateLister {
+		err := i.Kubeflow().V2beta1().MPIJobTemplates().Informer().GetIndexer().Add(template)
+		if err != nil {
+			fmt.Println("Failed to create mpijob template")
+		}
+	}
+
 	return c, i, k8sI
 }
 
@@ -381,6 +417,10 @@ func (f *fixture) expectUpdateMPIJobStatusAction(mpiJob *kubeflow.MPIJob) {
 	f.actions = append(f.actions, action)
 }
 
+func (f *fixture) expectUpdateMPIJobAction(mpiJob *kubeflow.MPIJob) {
+	f.actions = append(f.actions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "mpijobs"}, mpiJob.Namespace, mpiJob))
+}
+
 func (f *fixture) setUpMPIJob(mpiJob *kubeflow.MPIJob) {
 	f.mpiJobLister = append(f.mpiJobLister, mpiJob)
 	f.objects = append(f.objects, mpiJob)
@@ -457,7 +497,7 @@ func TestDoNothingWithInvalidMPIJob(t *testing.T) {
 }
 
 func TestAllResourcesCreated(t *testing.T) {
-	impls := []kubeflow.MPIImplementation{kubeflow.MPIImplementationOpenMPI, kubeflow.MPIImplementationIntel}
+	impls := []kubeflow.MPIImplementation{kubeflow.MPIImplementationOpenMPI, kubeflow.MPIImplementationIntel, kubeflow.MPIImplementationCharm}
 	for _, implementation := range impls {
 		t.Run(string(implementation), func(t *testing.T) {
 			f := newFixture(t)
@@ -470,8 +510,8 @@ func TestAllResourcesCreated(t *testing.T) {
 			mpiJobCopy := mpiJob.DeepCopy()
 			scheme.Scheme.Default(mpiJobCopy)
 			f.expectCreateServiceAction(newWorkersService(mpiJobCopy))
-			cfgMap := newConfigMap(mpiJobCopy, 5)
-			updateDiscoverHostsInConfigMap(cfgMap, mpiJob, nil)
+			cfgMap := newConfigMap(mpiJobCopy, 5, nil, nil)
+			updateDiscoverHostsInConfigMap(cfgMap, mpiJob, nil, nil)
 			f.expectCreateConfigMapAction(cfgMap)
 			secret, err := newSSHAuthSecret(mpiJobCopy)
 			if err != nil {
@@ -498,6 +538,511 @@ func TestAllResourcesCreated(t *testing.T) {
 	}
 }
 
+func TestLauncherWaitsForWorkerDNS(t *testing.T) {
+	f := newFixture(t)
+	f.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if host == "foo-worker-1.foo-worker.default.svc" {
+			return nil, errors.New("no such host")
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+	now := metav1.Now()
+	mpiJob := newMPIJob("foo", newInt32(2), &now, nil)
+	f.setUpMPIJob(mpiJob)
+
+	fmjc := f.newFakeMPIJobController()
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	f.expectCreateServiceAction(newWorkersService(mpiJobCopy))
+	cfgMap := newConfigMap(mpiJobCopy, 2, nil, nil)
+	updateDiscoverHostsInConfigMap(cfgMap, mpiJob, nil, nil)
+	f.expectCreateConfigMapAction(cfgMap)
+	secret, err := newSSHAuthSecret(mpiJobCopy)
+	if err != nil {
+		t.Fatalf("Failed creating secret")
+	}
+	f.expectCreateSecretAction(secret)
+	for i := 0; i < 2; i++ {
+		f.expectCreatePodAction(fmjc.newWorker(mpiJobCopy, i))
+	}
+	// The launcher is not created.
+
+	mpiJobCopy.Status.Conditions = []common.JobCondition{newCondition(common.JobCreated, mpiJobCreatedReason, "MPIJob default/foo is created.")}
+	mpiJobCopy.Status.ReplicaStatuses = map[common.ReplicaType]*common.ReplicaStatus{
+		common.ReplicaType(kubeflow.MPIReplicaTypeWorker): {},
+	}
+	f.expectUpdateMPIJobStatusAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestLauncherWaitsForWorkerAddresses(t *testing.T) {
+	f := newFixture(t)
+	now := metav1.Now()
+	mpiJob := newMPIJob("foo", newInt32(2), &now, nil)
+	mpiJob.Spec.HostDiscoveryNetwork = "sriov"
+	f.setUpMPIJob(mpiJob)
+
+	fmjc := f.newFakeMPIJobController()
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	f.expectCreateServiceAction(newWorkersService(mpiJobCopy))
+	cfgMap := newConfigMap(mpiJobCopy, 2, nil, nil)
+	updateDiscoverHostsInConfigMap(cfgMap, mpiJob, nil, nil)
+	f.expectCreateConfigMapAction(cfgMap)
+	secret, err := newSSHAuthSecret(mpiJobCopy)
+	if err != nil {
+		t.Fatalf("Failed creating secret")
+	}
+	f.expectCreateSecretAction(secret)
+	for i := 0; i < 2; i++ {
+		f.expectCreatePodAction(fmjc.newWorker(mpiJobCopy, i))
+	}
+	// The launcher is not created.
+
+	mpiJobCopy.Status.Conditions = []common.JobCondition{newCondition(common.JobCreated, mpiJobCreatedReason, "MPIJob default/foo is created.")}
+	mpiJobCopy.Status.ReplicaStatuses = map[common.ReplicaType]*common.ReplicaStatus{
+		common.ReplicaType(kubeflow.MPIReplicaTypeWorker): {},
+	}
+	f.expectUpdateMPIJobStatusAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestWorkerWithoutAddress(t *testing.T) {
+	job := newMPIJob("foo", newInt32(2), nil, nil)
+	withAddress := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-worker-0",
+			Namespace: metav1.NamespaceDefault,
+			Annotations: map[string]string{
+				networkStatusAnnotation: `[{"name":"default/sriov","ips":["192.168.1.5"]}]`,
+			},
+		},
+	}
+	withoutAddress := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-worker-1",
+			Namespace: metav1.NamespaceDefault,
+		},
+	}
+	cases := map[string]struct {
+		network string
+		workers []*corev1.Pod
+		want    string
+	}{
+		"no network": {
+			workers: []*corev1.Pod{withAddress, withoutAddress},
+		},
+		"all with address": {
+			network: "sriov",
+			workers: []*corev1.Pod{withAddress},
+		},
+		"worker without address": {
+			network: "sriov",
+			workers: []*corev1.Pod{withAddress, withoutAddress},
+			want:    "foo-worker-1",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := job.DeepCopy()
+			job.Spec.HostDiscoveryNetwork = tc.network
+			if got := workerWithoutAddress(job, tc.workers); got != tc.want {
+				t.Errorf("workerWithoutAddress returned %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnresolvableWorkerDoesNotWait(t *testing.T) {
+	lookups := make(chan string, 10)
+	resolve := make(chan struct{})
+	c := &MPIJobController{
+		queue:       workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		workerHosts: newWorkerHosts(),
+		lookupHost: func(_ context.Context, host string) ([]string, error) {
+			lookups <- host
+			<-resolve
+			return []string{"10.0.0.1"}, nil
+		},
+	}
+	defer c.queue.ShutDown()
+	job := newMPIJob("foo", newInt32(1), nil, nil)
+	scheme.Scheme.Default(job)
+	workers := []*corev1.Pod{c.newWorker(job, 0)}
+	key := getKey(job, t)
+	wantFQDN := "foo-worker-0.foo-worker.default.svc"
+
+	// The lookup is in flight.
+	for i := 0; i < 2; i++ {
+		if got := c.unresolvableWorker(key, job, workers); got != wantFQDN {
+			t.Errorf("unresolvableWorker returned %q, want %q", got, wantFQDN)
+		}
+	}
+	if got := <-lookups; got != wantFQDN {
+		t.Errorf("Looked up %q, want %q", got, wantFQDN)
+	}
+
+	close(resolve)
+	item, _ := c.queue.Get()
+	if item != key {
+		t.Errorf("Queued %v, want %q", item, key)
+	}
+	c.queue.Done(item)
+	if got := c.unresolvableWorker(key, job, workers); got != "" {
+		t.Errorf("unresolvableWorker returned %q, want none", got)
+	}
+	if len(lookups) > 0 {
+		t.Errorf("Unexpected lookup of %q", <-lookups)
+	}
+}
+
+func TestMPIJobWaitsForDependencies(t *testing.T) {
+	f := newFixture(t)
+	dep := newMPIJob("bar", newInt32(1), nil, nil)
+	dep.Status.Conditions = []common.JobCondition{newCondition(common.JobCreated, mpiJobCreatedReason, "")}
+	f.setUpMPIJob(dep)
+	mpiJob := newMPIJob("foo", newInt32(1), nil, nil)
+	mpiJob.Spec.DependsOn = []string{"bar", "baz"}
+	f.setUpMPIJob(mpiJob)
+
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	mpiJobCopy.Status.Conditions = []common.JobCondition{
+		newCondition(common.JobCreated, mpiJobCreatedReason, "MPIJob default/foo is created."),
+		newCondition(mpiJobWaiting, dependenciesPendingReason, "MPIJob default/foo is waiting for MPIJobs bar, baz to succeed."),
+	}
+	f.expectUpdateMPIJobStatusAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestMPIJobDependencyFailed(t *testing.T) {
+	dep := newMPIJob("bar", newInt32(1), nil, nil)
+	dep.Status.Conditions = []common.JobCondition{newCondition(common.JobFailed, mpiJobFailedReason, "")}
+	job := newMPIJob("foo", newInt32(1), nil, nil)
+	job.Spec.DependsOn = []string{"bar"}
+
+	f := newFixture(t)
+	f.setUpMPIJob(dep)
+	c, _, _ := f.newController("")
+	ready, err := c.checkDependencies(job)
+	if err != nil {
+		t.Fatalf("Failed checking dependencies: %v", err)
+	}
+	if ready {
+		t.Errorf("MPIJob is ready to start despite its failed dependency")
+	}
+	if !isFailed(job.Status) || job.Status.CompletionTime == nil {
+		t.Errorf("MPIJob wasn't marked as failed: %+v", job.Status)
+	}
+	if cond := getCondition(job.Status, mpiJobWaiting); cond == nil || cond.Status != corev1.ConditionFalse {
+		t.Errorf("Unexpected Waiting condition %+v", cond)
+	}
+}
+
+func TestMPIJobDependencyCycle(t *testing.T) {
+	cases := map[string]struct {
+		barStarted bool
+		wantFailed bool
+	}{
+		"cycle": {
+			wantFailed: true,
+		},
+		"dependency in the cycle started": {
+			barStarted: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var start *metav1.Time
+			if tc.barStarted {
+				now := metav1.Now()
+				start = &now
+			}
+			bar := newMPIJob("bar", newInt32(1), start, nil)
+			bar.Spec.DependsOn = []string{"baz"}
+			baz := newMPIJob("baz", newInt32(1), nil, nil)
+			baz.Spec.DependsOn = []string{"foo"}
+			job := newMPIJob("foo", newInt32(1), nil, nil)
+			job.Spec.DependsOn = []string{"bar"}
+
+			f := newFixture(t)
+			f.setUpMPIJob(bar)
+			f.setUpMPIJob(baz)
+			f.setUpMPIJob(job)
+			c, _, _ := f.newController("")
+			ready, err := c.checkDependencies(job)
+			if err != nil {
+				t.Fatalf("Failed checking dependencies: %v", err)
+			}
+			if ready {
+				t.Errorf("MPIJob is ready to start despite its pending dependency")
+			}
+			if got := isFailed(job.Status); got != tc.wantFailed {
+				t.Errorf("MPIJob failed: %t, want %t", got, tc.wantFailed)
+			}
+			if tc.wantFailed {
+				cond := getCondition(job.Status, common.JobFailed)
+				if cond == nil || cond.Reason != dependencyCycleReason {
+					t.Errorf("Unexpected Failed condition %+v", cond)
+				}
+				wantMsg := "MPIJob default/foo failed because its dependencies form a cycle: foo -> bar -> baz -> foo."
+				if cond != nil && cond.Message != wantMsg {
+					t.Errorf("Failed condition has message %q, want %q", cond.Message, wantMsg)
+				}
+			}
+		})
+	}
+}
+
+func TestMPIJobFromTemplate(t *testing.T) {
+	recordedSpec := newMPIJob("tmpl", newInt32(4), nil, nil).Spec
+	recorded, err := json.Marshal(recordedSpec)
+	if err != nil {
+		t.Fatalf("Serializing template spec: %v", err)
+	}
+	changedSpec := recordedSpec.DeepCopy()
+	changedSpec.SlotsPerWorker = newInt32(3)
+	cases := map[string]struct {
+		templateSpec *kubeflow.MPIJobSpec
+	}{
+		"template unchanged": {
+			templateSpec: &recordedSpec,
+		},
+		"template changed after start": {
+			templateSpec: changedSpec,
+		},
+		"template deleted after start": {},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			if tc.templateSpec != nil {
+				f.templateLister = append(f.templateLister, &kubeflow.MPIJobTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "blessed", Namespace: metav1.NamespaceDefault},
+					Spec:       *tc.templateSpec,
+				})
+			}
+			now := metav1.Now()
+			mpiJob := &kubeflow.MPIJob{
+				TypeMeta: metav1.TypeMeta{APIVersion: kubeflow.SchemeGroupVersion.String()},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "foo",
+					Namespace:   metav1.NamespaceDefault,
+					Finalizers:  []string{cleanupFinalizer},
+					Annotations: map[string]string{templateSpecAnnotation: string(recorded)},
+				},
+				Spec: kubeflow.MPIJobSpec{
+					TemplateName: "blessed",
+					MPIReplicaSpecs: map[kubeflow.MPIReplicaType]*common.ReplicaSpec{
+						kubeflow.MPIReplicaTypeWorker: {Replicas: newInt32(2)},
+					},
+				},
+				Status: common.JobStatus{StartTime: &now},
+			}
+			f.setUpMPIJob(mpiJob)
+
+			fmjc := f.newFakeMPIJobController()
+			mpiJobCopy := mpiJob.DeepCopy()
+			spec, err := mergeMPIJobSpec(&recordedSpec, &mpiJob.Spec)
+			if err != nil {
+				t.Fatalf("Merging template: %v", err)
+			}
+			mpiJobCopy.Spec = spec
+			scheme.Scheme.Default(mpiJobCopy)
+			f.expectCreateServiceAction(newWorkersService(mpiJobCopy))
+			cfgMap := newConfigMap(mpiJobCopy, 2, nil, nil)
+			updateDiscoverHostsInConfigMap(cfgMap, mpiJobCopy, nil, nil)
+			f.expectCreateConfigMapAction(cfgMap)
+			secret, err := newSSHAuthSecret(mpiJobCopy)
+			if err != nil {
+				t.Fatalf("Failed creating secret")
+			}
+			f.expectCreateSecretAction(secret)
+			for i := 0; i < 2; i++ {
+				f.expectCreatePodAction(fmjc.newWorker(mpiJobCopy, i))
+			}
+			f.expectCreateJobAction(fmjc.newLauncherJob(mpiJobCopy))
+
+			mpiJobCopy.Status.Conditions = []common.JobCondition{newCondition(common.JobCreated, mpiJobCreatedReason, "MPIJob default/foo is created.")}
+			mpiJobCopy.Status.ReplicaStatuses = map[common.ReplicaType]*common.ReplicaStatus{
+				common.ReplicaType(kubeflow.MPIReplicaTypeLauncher): {},
+				common.ReplicaType(kubeflow.MPIReplicaTypeWorker):   {},
+			}
+			f.expectUpdateMPIJobStatusAction(mpiJobCopy)
+
+			f.run(getKey(mpiJob, t))
+		})
+	}
+}
+
+func TestRecordTemplateSpec(t *testing.T) {
+	oldSpec := newMPIJob("tmpl", newInt32(4), nil, nil).Spec
+	old, err := json.Marshal(oldSpec)
+	if err != nil {
+		t.Fatalf("Serializing template spec: %v", err)
+	}
+	templateSpec := oldSpec.DeepCopy()
+	templateSpec.SlotsPerWorker = newInt32(3)
+	current, err := json.Marshal(templateSpec)
+	if err != nil {
+		t.Fatalf("Serializing template spec: %v", err)
+	}
+	cases := map[string]struct {
+		annotations map[string]string
+	}{
+		"not recorded": {},
+		"template changed before start": {
+			annotations: map[string]string{templateSpecAnnotation: string(old)},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			f.templateLister = append(f.templateLister, &kubeflow.MPIJobTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "blessed", Namespace: metav1.NamespaceDefault},
+				Spec:       *templateSpec,
+			})
+			mpiJob := newMPIJob("foo", newInt32(2), nil, nil)
+			mpiJob.Annotations = tc.annotations
+			mpiJob.Spec.TemplateName = "blessed"
+			f.setUpMPIJob(mpiJob)
+
+			mpiJobCopy := mpiJob.DeepCopy()
+			mpiJobCopy.Annotations = map[string]string{templateSpecAnnotation: string(current)}
+			f.expectUpdateMPIJobAction(mpiJobCopy)
+
+			f.run(getKey(mpiJob, t))
+		})
+	}
+}
+
+func TestMPIJobTemplateNotFound(t *testing.T) {
+	f := newFixture(t)
+	mpiJob := newMPIJob("foo", newInt32(1), nil, nil)
+	mpiJob.Spec.TemplateName = "missing"
+	f.setUpMPIJob(mpiJob)
+	f.run(getKey(mpiJob, t))
+}
+
+func TestMergeMPIJobSpec(t *testing.T) {
+	template := kubeflow.MPIJobSpec{
+		SlotsPerWorker: newInt32(2),
+		RunPolicy: common.RunPolicy{
+			BackoffLimit: newInt32(3),
+		},
+		SSHAuthMountPath: "/home/mpiuser/.ssh",
+		MPIReplicaSpecs: map[kubeflow.MPIReplicaType]*common.ReplicaSpec{
+			kubeflow.MPIReplicaTypeLauncher: {
+				Replicas: newInt32(1),
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "hpc"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:    "mpi",
+							Image:   "blessed:v1",
+							Command: []string{"mpirun"},
+							Env:     []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}},
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+							},
+						}},
+						Volumes:            []corev1.Volume{{Name: "scratch"}},
+						NodeSelector:       map[string]string{"pool": "hpc"},
+						Tolerations:        []corev1.Toleration{{Key: "hpc", Effect: corev1.TaintEffectNoSchedule}},
+						ServiceAccountName: "mpi-launcher",
+						PriorityClassName:  "batch",
+					},
+				},
+			},
+		},
+	}
+	overrides := kubeflow.MPIJobSpec{
+		TemplateName: "blessed",
+		RunPolicy: common.RunPolicy{
+			ActiveDeadlineSeconds: newInt64(60),
+		},
+		MPIReplicaSpecs: map[kubeflow.MPIReplicaType]*common.ReplicaSpec{
+			kubeflow.MPIReplicaTypeLauncher: {
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"run": "42"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "mpi",
+								Args: []string{"-n", "4", "app"},
+								Env:  []corev1.EnvVar{{Name: "B", Value: "3"}},
+								Resources: corev1.ResourceRequirements{
+									Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+								},
+							},
+							{Name: "sidecar", Image: "logger"},
+						},
+						Volumes:           []corev1.Volume{{Name: "data"}},
+						NodeSelector:      map[string]string{"zone": "a"},
+						PriorityClassName: "urgent",
+					},
+				},
+			},
+			kubeflow.MPIReplicaTypeWorker: {
+				Replicas: newInt32(4),
+			},
+		},
+	}
+	want := kubeflow.MPIJobSpec{
+		SlotsPerWorker: newInt32(2),
+		RunPolicy: common.RunPolicy{
+			BackoffLimit:          newInt32(3),
+			ActiveDeadlineSeconds: newInt64(60),
+		},
+		TemplateName:     "blessed",
+		SSHAuthMountPath: "/home/mpiuser/.ssh",
+		MPIReplicaSpecs: map[kubeflow.MPIReplicaType]*common.ReplicaSpec{
+			kubeflow.MPIReplicaTypeLauncher: {
+				Replicas: newInt32(1),
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "hpc", "run": "42"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:    "mpi",
+								Image:   "blessed:v1",
+								Command: []string{"mpirun"},
+								Args:    []string{"-n", "4", "app"},
+								Env:     []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "3"}},
+								Resources: corev1.ResourceRequirements{
+									Limits: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("1"),
+										corev1.ResourceMemory: resource.MustParse("1Gi"),
+									},
+								},
+							},
+							{Name: "sidecar", Image: "logger"},
+						},
+						Volumes:            []corev1.Volume{{Name: "data"}, {Name: "scratch"}},
+						NodeSelector:       map[string]string{"pool": "hpc", "zone": "a"},
+						Tolerations:        []corev1.Toleration{{Key: "hpc", Effect: corev1.TaintEffectNoSchedule}},
+						ServiceAccountName: "mpi-launcher",
+						PriorityClassName:  "urgent",
+					},
+				},
+			},
+			kubeflow.MPIReplicaTypeWorker: {
+				Replicas: newInt32(4),
+			},
+		},
+	}
+	got, err := mergeMPIJobSpec(&template, &overrides)
+	if err != nil {
+		t.Fatalf("Merging template: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected merged spec (-want,+got):\n%s", diff)
+	}
+}
+
 func TestLauncherNotControlledByUs(t *testing.T) {
 	f := newFixture(t)
 	startTime := metav1.Now()
@@ -610,25 +1155,341 @@ func TestLauncherFailed(t *testing.T) {
 	f.run(getKey(mpiJob, t))
 }
 
-func TestConfigMapNotControlledByUs(t *testing.T) {
-	f := newFixture(t)
+func newRequeueMPIJob(requeueCount string) *kubeflow.MPIJob {
 	startTime := metav1.Now()
-	completionTime := metav1.Now()
+	mpiJob := newMPIJob("test", newInt32(2), &startTime, nil)
+	mpiJob.Spec.RequeueOnFailure = &kubeflow.RequeuePolicy{MaxRequeues: 2, BackoffSeconds: newInt32(0)}
+	if requeueCount != "" {
+		mpiJob.Annotations = map[string]string{requeueCountAnnotation: requeueCount}
+	}
+	msg := fmt.Sprintf("MPIJob %s/%s is created.", mpiJob.Namespace, mpiJob.Name)
+	updateMPIJobConditions(mpiJob, common.JobCreated, mpiJobCreatedReason, msg)
+	return mpiJob
+}
 
-	var replicas int32 = 64
-	mpiJob := newMPIJob("test", &replicas, &startTime, &completionTime)
+func newFailedLauncher(fmjc *MPIJobController, mpiJob *kubeflow.MPIJob, reason string) *batchv1.Job {
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	launcher := fmjc.newLauncherJob(mpiJobCopy)
+	launcher.Status.Conditions = append(launcher.Status.Conditions, batchv1.JobCondition{
+		Type:               batchv1.JobFailed,
+		Status:             corev1.ConditionTrue,
+		Reason:             reason,
+		LastTransitionTime: metav1.Now(),
+	})
+	return launcher
+}
+
+func TestLauncherFailedRecordsRequeue(t *testing.T) {
+	f := newFixture(t)
+	mpiJob := newRequeueMPIJob("")
+	mpiJob.Spec.RequeueOnFailure.BackoffSeconds = newInt32(60)
 	f.setUpMPIJob(mpiJob)
-	f.setUpService(newWorkersService(mpiJob))
+	fmjc := f.newFakeMPIJobController()
+	launcher := newFailedLauncher(fmjc, mpiJob, jobBackoffLimitExceededReason)
+	f.setUpLauncher(launcher)
 
-	configMap := newConfigMap(mpiJob, replicas)
-	updateDiscoverHostsInConfigMap(configMap, mpiJob, nil)
-	configMap.OwnerReferences = nil
-	f.setUpConfigMap(configMap)
+	mpiJobCopy := mpiJob.DeepCopy()
+	failedAt := launcher.Status.Conditions[0].LastTransitionTime
+	mpiJobCopy.Annotations = map[string]string{
+		requeueCountAnnotation: "1",
+		requeueAfterAnnotation: failedAt.Add(time.Minute).UTC().Format(time.RFC3339),
+	}
+	f.expectUpdateMPIJobAction(mpiJobCopy)
 
-	f.runExpectError(getKey(mpiJob, t))
+	f.run(getKey(mpiJob, t))
 }
 
-func TestWorkerServiceNotControlledByUs(t *testing.T) {
+func TestLauncherFailedRequeueCondition(t *testing.T) {
+	f := newFixture(t)
+	mpiJob := newRequeueMPIJob("1")
+	mpiJob.Annotations[requeueAfterAnnotation] = time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	f.setUpMPIJob(mpiJob)
+	fmjc := f.newFakeMPIJobController()
+	launcher := newFailedLauncher(fmjc, mpiJob, jobBackoffLimitExceededReason)
+	launcher.Annotations = nil
+	f.setUpLauncher(launcher)
+
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	msg := "Launcher test-launcher exceeded its backoff limit, requeueing MPIJob default/test (1/2)"
+	updateMPIJobConditions(mpiJobCopy, common.JobRestarting, mpiJobRequeuedReason, msg)
+	f.expectUpdateMPIJobStatusAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestLauncherFailedRequeue(t *testing.T) {
+	cases := map[string]struct {
+		backoffPending bool
+		launcherGone   bool
+	}{
+		"backoff elapsed": {},
+		"backoff pending": {
+			backoffPending: true,
+		},
+		"launcher gone": {
+			launcherGone: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			mpiJob := newRequeueMPIJob("1")
+			after := time.Now().Add(-time.Second)
+			if tc.backoffPending {
+				after = time.Now().Add(time.Minute)
+			}
+			mpiJob.Annotations[requeueAfterAnnotation] = after.UTC().Format(time.RFC3339)
+			msg := "Launcher test-launcher exceeded its backoff limit, requeueing MPIJob default/test (1/2)"
+			updateMPIJobConditions(mpiJob, common.JobRestarting, mpiJobRequeuedReason, msg)
+			f.setUpMPIJob(mpiJob)
+			fmjc := f.newFakeMPIJobController()
+			if !tc.launcherGone {
+				// The launcher belongs to the failed attempt.
+				launcher := newFailedLauncher(fmjc, mpiJob, jobBackoffLimitExceededReason)
+				launcher.Annotations = nil
+				f.setUpLauncher(launcher)
+			}
+			mpiJobCopy := mpiJob.DeepCopy()
+			scheme.Scheme.Default(mpiJobCopy)
+			for i := 0; i < 2; i++ {
+				f.setUpPod(fmjc.newWorker(mpiJobCopy, i))
+			}
+
+			if !tc.backoffPending {
+				if !tc.launcherGone {
+					f.kubeActions = append(f.kubeActions,
+						core.NewDeleteAction(schema.GroupVersionResource{Group: "batch", Resource: "jobs"}, mpiJob.Namespace, "test-launcher"))
+				}
+				f.kubeActions = append(f.kubeActions,
+					core.NewDeleteAction(schema.GroupVersionResource{Resource: "pods"}, mpiJob.Namespace, "test-worker-0"),
+					core.NewDeleteAction(schema.GroupVersionResource{Resource: "pods"}, mpiJob.Namespace, "test-worker-1"))
+				mpiJobCopy = mpiJob.DeepCopy()
+				delete(mpiJobCopy.Annotations, requeueAfterAnnotation)
+				f.expectUpdateMPIJobAction(mpiJobCopy)
+			}
+
+			f.run(getKey(mpiJob, t))
+		})
+	}
+}
+
+func TestLauncherOfRequeuedAttempt(t *testing.T) {
+	f := newFixture(t)
+	mpiJob := newRequeueMPIJob("1")
+	f.setUpMPIJob(mpiJob)
+	fmjc := f.newFakeMPIJobController()
+	// The launcher of the first attempt is still in the cache.
+	launcher := newFailedLauncher(fmjc, mpiJob, jobBackoffLimitExceededReason)
+	launcher.Annotations = nil
+	f.setUpLauncher(launcher)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestCanRequeue(t *testing.T) {
+	cases := map[string]struct {
+		requeueCount string
+		reason       string
+		want         bool
+	}{
+		"backoff limit exceeded": {
+			reason: jobBackoffLimitExceededReason,
+			want:   true,
+		},
+		"deadline exceeded": {
+			reason: "DeadlineExceeded",
+		},
+		"max requeues reached": {
+			requeueCount: "2",
+			reason:       jobBackoffLimitExceededReason,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			mpiJob := newRequeueMPIJob(tc.requeueCount)
+			launcher := newFailedLauncher(f.newFakeMPIJobController(), mpiJob, tc.reason)
+			if got := canRequeue(mpiJob, launcher); got != tc.want {
+				t.Errorf("canRequeue() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMPIJobPastDeadline(t *testing.T) {
+	f := newFixture(t)
+	completionTime := metav1.Now()
+	mpiJob := newMPIJob("test", newInt32(2), nil, &completionTime)
+	mpiJob.CreationTimestamp = metav1.NewTime(completionTime.Add(-time.Hour))
+	mpiJob.Spec.DeadlineSeconds = newInt64(600)
+	f.setUpMPIJob(mpiJob)
+
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	msg := fmt.Sprintf("MPIJob %s/%s is created.", mpiJob.Namespace, mpiJob.Name)
+	updateMPIJobConditions(mpiJobCopy, common.JobCreated, mpiJobCreatedReason, msg)
+	msg = "MPIJob default/test exceeded its deadline of 600 seconds"
+	updateMPIJobConditions(mpiJobCopy, common.JobFailed, jobDeadlineExceededReason, msg)
+	f.expectUpdateMPIJobStatusAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestLauncherActiveDeadline(t *testing.T) {
+	now := time.Now()
+	cases := map[string]struct {
+		activeDeadline *int64
+		deadline       *int64
+		want           *int64
+	}{
+		"no deadlines": {},
+		"active deadline only": {
+			activeDeadline: newInt64(100),
+			want:           newInt64(100),
+		},
+		"time left in job deadline": {
+			deadline: newInt64(600),
+			want:     newInt64(300),
+		},
+		"shorter active deadline": {
+			activeDeadline: newInt64(100),
+			deadline:       newInt64(600),
+			want:           newInt64(100),
+		},
+		"job deadline passed": {
+			deadline: newInt64(60),
+			want:     newInt64(1),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := newMPIJob("test", newInt32(1), nil, nil)
+			job.CreationTimestamp = metav1.NewTime(now.Add(-5 * time.Minute))
+			job.Spec.RunPolicy.ActiveDeadlineSeconds = tc.activeDeadline
+			job.Spec.DeadlineSeconds = tc.deadline
+			got := launcherActiveDeadline(job, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Unexpected active deadline (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTTLAfterFinished(t *testing.T) {
+	f := newFixture(t)
+	startTime := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	completionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	var replicas int32 = 2
+	mpiJob := newMPIJob("test", &replicas, &startTime, &completionTime)
+	cleanPodPolicyNone := common.CleanPodPolicyNone
+	mpiJob.Spec.RunPolicy.CleanPodPolicy = &cleanPodPolicyNone
+	mpiJob.Spec.RunPolicy.TTLSecondsAfterFinished = newInt32(600)
+	updateMPIJobConditions(mpiJob, common.JobSucceeded, mpiJobSucceededReason, "")
+	f.setUpMPIJob(mpiJob)
+
+	fmjc := f.newFakeMPIJobController()
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	for i := 0; i < int(replicas); i++ {
+		f.setUpPod(fmjc.newWorker(mpiJobCopy, i))
+	}
+	f.setUpService(newWorkersService(mpiJobCopy))
+	f.setUpConfigMap(newConfigMap(mpiJobCopy, replicas, nil, nil))
+	secret, err := newSSHAuthSecret(mpiJobCopy)
+	if err != nil {
+		t.Fatalf("Creating SSH auth Secret: %v", err)
+	}
+	f.setUpSecret(secret)
+
+	for _, r := range []string{"pods", "pods", "services", "configmaps", "secrets"} {
+		f.kubeActions = append(f.kubeActions, core.NewDeleteAction(schema.GroupVersionResource{Resource: r}, mpiJob.Namespace, ""))
+	}
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestOwnedObjectsOnly(t *testing.T) {
+	owned := map[string]string{common.OperatorNameLabel: kubeflow.OperatorName}
+	cases := map[string]struct {
+		ownedObjectsOnly bool
+		wantUnowned      bool
+	}{
+		"all objects": {
+			wantUnowned: true,
+		},
+		"owned objects only": {
+			ownedObjectsOnly: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			f.ownedObjectsOnly = tc.ownedObjectsOnly
+			f.kubeObjects = []runtime.Object{
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owned", Namespace: metav1.NamespaceDefault, Labels: owned}},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: metav1.NamespaceDefault}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "owned", Namespace: metav1.NamespaceDefault, Labels: owned}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: metav1.NamespaceDefault}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "owned", Namespace: metav1.NamespaceDefault, Labels: owned}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: metav1.NamespaceDefault}},
+			}
+			c, _, k8sI := f.newController("")
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			k8sI.Start(stopCh)
+			k8sI.WaitForCacheSync(stopCh)
+
+			getters := map[string]func(name string) error{
+				"ConfigMap": func(name string) error {
+					_, err := c.configMapLister.ConfigMaps(metav1.NamespaceDefault).Get(name)
+					return err
+				},
+				"Secret": func(name string) error {
+					_, err := c.secretLister.Secrets(metav1.NamespaceDefault).Get(name)
+					return err
+				},
+				"Pod": func(name string) error {
+					_, err := c.podLister.Pods(metav1.NamespaceDefault).Get(name)
+					return err
+				},
+			}
+			for kind, get := range getters {
+				if err := get("owned"); err != nil {
+					t.Errorf("Getting owned %s: %v", kind, err)
+				}
+				err := get("unowned")
+				if tc.wantUnowned && err != nil {
+					t.Errorf("Getting unowned %s: %v", kind, err)
+				}
+				if !tc.wantUnowned && !apierrors.IsNotFound(err) {
+					t.Errorf("Getting unowned %s returned error %v, want not found", kind, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigMapNotControlledByUs(t *testing.T) {
+	f := newFixture(t)
+	startTime := metav1.Now()
+	completionTime := metav1.Now()
+
+	var replicas int32 = 64
+	mpiJob := newMPIJob("test", &replicas, &startTime, &completionTime)
+	f.setUpMPIJob(mpiJob)
+	f.setUpService(newWorkersService(mpiJob))
+
+	configMap := newConfigMap(mpiJob, replicas, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJob, nil, nil)
+	configMap.OwnerReferences = nil
+	f.setUpConfigMap(configMap)
+
+	f.runExpectError(getKey(mpiJob, t))
+}
+
+func TestWorkerServiceNotControlledByUs(t *testing.T) {
 	f := newFixture(t)
 	startTime := metav1.Now()
 	completionTime := metav1.Now()
@@ -660,13 +1521,13 @@ func TestLauncherServiceNotControlledByUs(t *testing.T) {
 	scheme.Scheme.Default(mpiJobCopy)
 	service := newWorkersService(mpiJobCopy)
 	f.setUpService(service)
-	configMap := newConfigMap(mpiJobCopy, replicas)
+	configMap := newConfigMap(mpiJobCopy, replicas, nil, nil)
 	secret, err := newSSHAuthSecret(mpiJobCopy)
 	if err != nil {
 		t.Fatalf("Creating SSH auth Secret: %v", err)
 	}
 	f.setUpSecret(secret)
-	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil, nil)
 	f.setUpConfigMap(configMap)
 	fmjc := f.newFakeMPIJobController()
 	for i := 0; i < int(replicas); i++ {
@@ -692,8 +1553,8 @@ func TestSecretNotControlledByUs(t *testing.T) {
 
 	mpiJobCopy := mpiJob.DeepCopy()
 	scheme.Scheme.Default(mpiJobCopy)
-	configMap := newConfigMap(mpiJobCopy, replicas)
-	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil)
+	configMap := newConfigMap(mpiJobCopy, replicas, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil, nil)
 	f.setUpConfigMap(configMap)
 	f.setUpService(newWorkersService(mpiJobCopy))
 
@@ -762,8 +1623,8 @@ func TestWorkerNotControlledByUs(t *testing.T) {
 
 	mpiJobCopy := mpiJob.DeepCopy()
 	scheme.Scheme.Default(mpiJobCopy)
-	configMap := newConfigMap(mpiJobCopy, replicas)
-	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil)
+	configMap := newConfigMap(mpiJobCopy, replicas, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil, nil)
 	f.setUpConfigMap(configMap)
 	f.setUpService(newWorkersService(mpiJobCopy))
 	secret, err := newSSHAuthSecret(mpiJobCopy)
@@ -793,8 +1654,8 @@ func TestLauncherActiveWorkerNotReady(t *testing.T) {
 
 	mpiJobCopy := mpiJob.DeepCopy()
 	scheme.Scheme.Default(mpiJobCopy)
-	configMap := newConfigMap(mpiJobCopy, replicas)
-	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil)
+	configMap := newConfigMap(mpiJobCopy, replicas, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, nil, nil)
 	f.setUpConfigMap(configMap)
 	f.setUpService(newWorkersService(mpiJobCopy))
 	secret, err := newSSHAuthSecret(mpiJobCopy)
@@ -868,8 +1729,8 @@ func TestLauncherActiveWorkerReady(t *testing.T) {
 		f.setUpPod(worker)
 	}
 
-	configMap := newConfigMap(mpiJobCopy, replicas)
-	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, runningPodList)
+	configMap := newConfigMap(mpiJobCopy, replicas, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, runningPodList, nil)
 	f.setUpConfigMap(configMap)
 
 	mpiJobCopy.Status.ReplicaStatuses = map[common.ReplicaType]*common.ReplicaStatus{
@@ -894,6 +1755,172 @@ func TestLauncherActiveWorkerReady(t *testing.T) {
 	f.run(getKey(mpiJob, t))
 }
 
+func TestScaleDownWorkers(t *testing.T) {
+	f := newFixture(t)
+	startTime := metav1.Now()
+	completionTime := metav1.Now()
+
+	var replicas int32 = 2
+	mpiJob := newMPIJob("test", &replicas, &startTime, &completionTime)
+	f.setUpMPIJob(mpiJob)
+
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	f.setUpService(newWorkersService(mpiJobCopy))
+	secret, err := newSSHAuthSecret(mpiJobCopy)
+	if err != nil {
+		t.Fatalf("Creating SSH auth secret: %v", err)
+	}
+	f.setUpSecret(secret)
+
+	fmjc := f.newFakeMPIJobController()
+	launcher := fmjc.newLauncherJob(mpiJobCopy)
+	launcherPod := mockJobPod(launcher)
+	launcherPod.Status.Phase = corev1.PodRunning
+	f.setUpLauncher(launcher)
+	f.setUpPod(launcherPod)
+
+	// The job was scaled down from 4 workers.
+	var runningPodList []*corev1.Pod
+	for i := 0; i < 4; i++ {
+		worker := fmjc.newWorker(mpiJobCopy, i)
+		worker.Status.Phase = corev1.PodRunning
+		runningPodList = append(runningPodList, worker)
+		f.setUpPod(worker)
+	}
+	configMap := newConfigMap(mpiJobCopy, 4, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, runningPodList, nil)
+	f.setUpConfigMap(configMap)
+
+	configMap = newConfigMap(mpiJobCopy, replicas, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, runningPodList, nil)
+	f.kubeActions = append(f.kubeActions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "configmaps"}, mpiJob.Namespace, configMap))
+	for i := int(replicas); i < 4; i++ {
+		f.kubeActions = append(f.kubeActions, core.NewDeleteAction(schema.GroupVersionResource{Resource: "pods"}, mpiJob.Namespace, workerName(mpiJob, i)))
+	}
+
+	mpiJobCopy.Status.ReplicaStatuses = map[common.ReplicaType]*common.ReplicaStatus{
+		common.ReplicaType(kubeflow.MPIReplicaTypeLauncher): {
+			Active: 1,
+		},
+		common.ReplicaType(kubeflow.MPIReplicaTypeWorker): {
+			Active: 2,
+		},
+	}
+	setUpMPIJobTimestamp(mpiJobCopy, &startTime, &completionTime)
+	msg := fmt.Sprintf("MPIJob %s/%s is created.", mpiJob.Namespace, mpiJob.Name)
+	updateMPIJobConditions(mpiJobCopy, common.JobCreated, mpiJobCreatedReason, msg)
+	msg = fmt.Sprintf("MPIJob %s/%s is running.", mpiJob.Namespace, mpiJob.Name)
+	updateMPIJobConditions(mpiJobCopy, common.JobRunning, mpiJobRunningReason, msg)
+	f.expectUpdateMPIJobStatusAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestWorkerSizeEvents(t *testing.T) {
+	cases := map[string]struct {
+		replicas   int32
+		recorded   *int32
+		live       []int
+		terminated []int
+		wantEvents []string
+		wantSize   int32
+	}{
+		"scale up": {
+			replicas: 4,
+			recorded: newInt32(2),
+			live:     []int{0, 1},
+			wantEvents: []string{
+				"Normal MPIJobExpanded MPIJob default/test expanded from 2 to 4 workers",
+			},
+			wantSize: 4,
+		},
+		"scale down": {
+			replicas: 2,
+			recorded: newInt32(4),
+			live:     []int{0, 1, 2, 3},
+			wantEvents: []string{
+				"Normal MPIJobShrunk MPIJob default/test shrunk from 4 to 2 workers",
+			},
+			wantSize: 2,
+		},
+		"scale down after controller restart": {
+			replicas: 2,
+			live:     []int{0, 1, 2, 3},
+			wantEvents: []string{
+				"Normal MPIJobShrunk MPIJob default/test shrunk from 4 to 2 workers",
+			},
+			wantSize: 2,
+		},
+		"resync during termination": {
+			replicas:   2,
+			recorded:   newInt32(2),
+			live:       []int{0, 1},
+			terminated: []int{2, 3},
+			wantSize:   2,
+		},
+		"worker eviction": {
+			replicas: 4,
+			recorded: newInt32(4),
+			live:     []int{0, 1},
+			wantSize: 4,
+		},
+		"retry of initial creation": {
+			replicas: 4,
+			live:     []int{0, 1},
+			wantSize: 4,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			mpiJob := newMPIJob("test", newInt32(tc.replicas), nil, nil)
+			scheme.Scheme.Default(mpiJob)
+			fmjc := f.newFakeMPIJobController()
+			for _, i := range tc.live {
+				f.setUpPod(fmjc.newWorker(mpiJob, i))
+			}
+			for _, i := range tc.terminated {
+				worker := fmjc.newWorker(mpiJob, i)
+				worker.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				f.setUpPod(worker)
+			}
+			c, _, _ := f.newController("")
+			recorder := record.NewFakeRecorder(10)
+			c.recorder = recorder
+			key := getKey(mpiJob, t)
+			if tc.recorded != nil {
+				c.workerSizes.set(key, *tc.recorded)
+			}
+
+			if _, err := c.getOrCreateWorker(mpiJob); err != nil {
+				t.Fatalf("Creating workers: %v", err)
+			}
+			close(recorder.Events)
+			var events []string
+			for e := range recorder.Events {
+				events = append(events, e)
+			}
+			if diff := cmp.Diff(tc.wantEvents, events); diff != "" {
+				t.Errorf("Unexpected events (-want,+got):\n%s", diff)
+			}
+			if size, _ := c.workerSizes.get(key); size != tc.wantSize {
+				t.Errorf("Recorded size %d, want %d", size, tc.wantSize)
+			}
+			for _, a := range f.kubeClient.Actions() {
+				if a.GetVerb() == "delete" {
+					name := a.(core.DeleteAction).GetName()
+					for _, i := range tc.terminated {
+						if name == workerName(mpiJob, i) {
+							t.Errorf("Deleted terminating worker %s", name)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestWorkerReady(t *testing.T) {
 	f := newFixture(t)
 	startTime := metav1.Now()
@@ -922,8 +1949,8 @@ func TestWorkerReady(t *testing.T) {
 		f.setUpPod(worker)
 	}
 
-	configMap := newConfigMap(mpiJobCopy, replicas)
-	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, runningPodList)
+	configMap := newConfigMap(mpiJobCopy, replicas, nil, nil)
+	updateDiscoverHostsInConfigMap(configMap, mpiJobCopy, runningPodList, nil)
 	f.setUpConfigMap(configMap)
 
 	expLauncher := fmjc.newLauncherJob(mpiJobCopy)
@@ -949,6 +1976,552 @@ func TestWorkerReady(t *testing.T) {
 	f.run(getKey(mpiJob, t))
 }
 
+func TestAddCleanupFinalizer(t *testing.T) {
+	f := newFixture(t)
+	mpiJob := newMPIJob("test", newInt32(1), nil, nil)
+	mpiJob.Finalizers = nil
+	f.setUpMPIJob(mpiJob)
+
+	mpiJobCopy := mpiJob.DeepCopy()
+	mpiJobCopy.Finalizers = []string{cleanupFinalizer}
+	f.expectUpdateMPIJobAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestRecordChildNamePrefix(t *testing.T) {
+	f := newFixture(t)
+	mpiJob := newMPIJob("this-name-is-waaaaaaaay-too-long-to-be-a-prefix-of-worker-names", newInt32(1), nil, nil)
+	f.setUpMPIJob(mpiJob)
+
+	mpiJobCopy := mpiJob.DeepCopy()
+	mpiJobCopy.Annotations = map[string]string{
+		childNamePrefixAnnotation: "this-name-is-waaaaaaaay-too-long-to-be-a-1cc8a542",
+	}
+	f.expectUpdateMPIJobAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestChildNames(t *testing.T) {
+	cases := map[string]struct {
+		name       string
+		wantPrefix string
+	}{
+		"short name": {
+			name:       "foo",
+			wantPrefix: "foo",
+		},
+		"long name": {
+			name:       "this-name-is-waaaaaaaay-too-long-to-be-a-prefix-of-worker-names",
+			wantPrefix: "this-name-is-waaaaaaaay-too-long-to-be-a-1cc8a542",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			c := f.newFakeMPIJobController()
+			job := newMPIJob(tc.name, newInt32(2), nil, nil)
+			scheme.Scheme.Default(job)
+			secret, err := newSSHAuthSecret(job)
+			if err != nil {
+				t.Fatalf("Creating SSH auth secret: %v", err)
+			}
+			got := []string{
+				newWorkersService(job).Name,
+				newLauncherService(job).Name,
+				newConfigMap(job, 2, nil, nil).Name,
+				secret.Name,
+				c.newWorker(job, 1).Name,
+				c.newWorker(job, 1).Spec.Subdomain,
+				c.newLauncherJob(job).Name,
+			}
+			want := []string{
+				tc.wantPrefix + "-worker",
+				tc.wantPrefix + "-launcher",
+				tc.wantPrefix + "-config",
+				tc.wantPrefix + "-ssh",
+				tc.wantPrefix + "-worker-1",
+				tc.wantPrefix + "-worker",
+				tc.wantPrefix + "-launcher",
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Unexpected names (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFinalizeMPIJob(t *testing.T) {
+	f := newFixture(t)
+	startTime := metav1.Now()
+	var replicas int32 = 2
+	mpiJob := newMPIJob("test", &replicas, &startTime, nil)
+	mpiJob.Finalizers = append(mpiJob.Finalizers, "example.com/other")
+	mpiJob.DeletionTimestamp = &startTime
+	f.setUpMPIJob(mpiJob)
+
+	fmjc := f.newFakeMPIJobController()
+	mpiJobCopy := mpiJob.DeepCopy()
+	scheme.Scheme.Default(mpiJobCopy)
+	f.setUpLauncher(fmjc.newLauncherJob(mpiJobCopy))
+	for i := 0; i < int(replicas); i++ {
+		f.setUpPod(fmjc.newWorker(mpiJobCopy, i))
+	}
+	f.setUpService(newWorkersService(mpiJobCopy))
+	f.setUpConfigMap(newConfigMap(mpiJobCopy, replicas, nil, nil))
+	// The Secret is not controlled by the MPIJob, so it must be kept.
+	secret, err := newSSHAuthSecret(mpiJobCopy)
+	if err != nil {
+		t.Fatalf("Creating SSH auth Secret: %v", err)
+	}
+	secret.OwnerReferences = nil
+	f.setUpSecret(secret)
+
+	for _, r := range []schema.GroupVersionResource{
+		{Group: "batch", Resource: "jobs"},
+		{Resource: "pods"},
+		{Resource: "pods"},
+		{Resource: "services"},
+		{Resource: "configmaps"},
+	} {
+		f.kubeActions = append(f.kubeActions, core.NewDeleteAction(r, mpiJob.Namespace, ""))
+	}
+	mpiJobCopy = mpiJob.DeepCopy()
+	mpiJobCopy.Finalizers = []string{"example.com/other"}
+	f.expectUpdateMPIJobAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestDeletedMPIJob(t *testing.T) {
+	f := newFixture(t)
+	c, _, _ := f.newController("")
+	mpiJob := newMPIJob("test", newInt32(1), nil, nil)
+	key := getKey(mpiJob, t)
+	c.ports.reservations[key] = 20000
+	c.workerSizes.set(key, 1)
+	c.cleanups.start(key)
+
+	c.deleteMPIJob(cache.DeletedFinalStateUnknown{Key: key, Obj: mpiJob})
+	item, _ := c.queue.Get()
+	if item != key {
+		t.Fatalf("Enqueued %v, want %s", item, key)
+	}
+	if err := c.syncHandler(key); err != nil {
+		t.Fatalf("Syncing deleted MPIJob: %v", err)
+	}
+	if _, ok := c.ports.reservations[key]; ok {
+		t.Errorf("SSH port reservation wasn't released")
+	}
+	if _, ok := c.workerSizes.get(key); ok {
+		t.Errorf("Worker size wasn't released")
+	}
+	if c.cleanups.keys.Has(key) {
+		t.Errorf("MPIJob is still pending cleanup")
+	}
+}
+
+func TestAllocateSSHPort(t *testing.T) {
+	f := newFixture(t)
+	f.hostNetworkPorts = utilnet.PortRange{Base: 20000, Size: 3}
+
+	running := newMPIJob("running", newInt32(1), nil, nil)
+	running.Annotations = map[string]string{sshPortAnnotation: "20000"}
+	f.setUpMPIJob(running)
+	finished := newMPIJob("finished", newInt32(1), nil, nil)
+	finished.Annotations = map[string]string{sshPortAnnotation: "20002"}
+	finished.Status.Conditions = []common.JobCondition{newCondition(common.JobSucceeded, mpiJobSucceededReason, "")}
+	f.setUpMPIJob(finished)
+	// The workers of this finished job were kept by the cleanPodPolicy.
+	finishedWithWorkers := newMPIJob("finished-with-workers", newInt32(1), nil, nil)
+	finishedWithWorkers.Annotations = map[string]string{sshPortAnnotation: "20001"}
+	finishedWithWorkers.Status.Conditions = []common.JobCondition{newCondition(common.JobSucceeded, mpiJobSucceededReason, "")}
+	f.setUpMPIJob(finishedWithWorkers)
+	fmjc := f.newFakeMPIJobController()
+	finishedCopy := finishedWithWorkers.DeepCopy()
+	scheme.Scheme.Default(finishedCopy)
+	f.setUpPod(fmjc.newWorker(finishedCopy, 0))
+
+	mpiJob := newMPIJob("test", newInt32(2), nil, nil)
+	mpiJob.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeWorker].Template.Spec.HostNetwork = true
+	f.setUpMPIJob(mpiJob)
+
+	// The port of the finished job without workers can be reused.
+	mpiJobCopy := mpiJob.DeepCopy()
+	mpiJobCopy.Annotations = map[string]string{sshPortAnnotation: "20002"}
+	f.expectUpdateMPIJobAction(mpiJobCopy)
+
+	f.run(getKey(mpiJob, t))
+}
+
+func TestNextFreePort(t *testing.T) {
+	cases := map[string]struct {
+		used     []int32
+		wantPort int32
+		wantOK   bool
+	}{
+		"empty": {
+			wantPort: 100,
+			wantOK:   true,
+		},
+		"gap": {
+			used:     []int32{100, 102},
+			wantPort: 101,
+			wantOK:   true,
+		},
+		"exhausted": {
+			used: []int32{100, 101, 102},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			port, ok := nextFreePort(utilnet.PortRange{Base: 100, Size: 3}, sets.NewInt32(tc.used...))
+			if port != tc.wantPort || ok != tc.wantOK {
+				t.Errorf("nextFreePort returned (%d, %t), want (%d, %t)", port, ok, tc.wantPort, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestSSHClientArgs(t *testing.T) {
+	cases := map[string]struct {
+		options     *kubeflow.SSHOptions
+		annotations map[string]string
+		want        string
+	}{
+		"defaults": {
+			want: "-o ConnectionAttempts=10",
+		},
+		"all options": {
+			options: &kubeflow.SSHOptions{
+				ConnectionAttempts:  newInt32(3),
+				ServerAliveInterval: newInt32(30),
+				ServerAliveCountMax: newInt32(5),
+				Ciphers:             []string{"aes128-gcm@openssh.com", "aes256-ctr"},
+				ProxyJump:           "mpiuser@bastion:2222",
+			},
+			want: "-o ConnectionAttempts=3 -o ServerAliveInterval=30 -o ServerAliveCountMax=5 -o Ciphers=aes128-gcm@openssh.com,aes256-ctr -o ProxyJump=mpiuser@bastion:2222",
+		},
+		"with ssh port": {
+			options: &kubeflow.SSHOptions{
+				ServerAliveInterval: newInt32(10),
+			},
+			annotations: map[string]string{sshPortAnnotation: "20005"},
+			want:        "-o ConnectionAttempts=10 -o ServerAliveInterval=10 -p 20005",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := newMPIJob("foo", newInt32(1), nil, nil)
+			job.Annotations = tc.annotations
+			job.Spec.SSHOptions = tc.options
+			if got := sshClientArgs(job); got != tc.want {
+				t.Errorf("sshClientArgs returned %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewLauncherAndWorkerWithSSHPort(t *testing.T) {
+	job := newMPIJob("foo", newInt32(1), nil, nil)
+	job.Annotations = map[string]string{sshPortAnnotation: "20005"}
+	scheme.Scheme.Default(job)
+	ctrl := &MPIJobController{}
+
+	launcher := ctrl.newLauncherJob(job)
+	wantEnv := []corev1.EnvVar{
+		{Name: openMPIRshArgsEnv, Value: "-o ConnectionAttempts=10 -p 20005"},
+		{Name: sshPortEnv, Value: "20005"},
+	}
+	for _, want := range wantEnv {
+		if !hasEnvVar(launcher.Spec.Template.Spec.Containers[0].Env, want) {
+			t.Errorf("Launcher is missing environment variable %+v", want)
+		}
+	}
+
+	worker := ctrl.newWorker(job, 0)
+	wantCommand := []string{"/usr/sbin/sshd", "-De", "-p", "20005"}
+	if diff := cmp.Diff(wantCommand, worker.Spec.Containers[0].Command); diff != "" {
+		t.Errorf("Unexpected worker command (-want,+got):\n%s", diff)
+	}
+	if !hasEnvVar(worker.Spec.Containers[0].Env, wantEnv[1]) {
+		t.Errorf("Worker is missing environment variable %+v", wantEnv[1])
+	}
+}
+
+func TestNewCharmLauncherWithSSHArgs(t *testing.T) {
+	job := newMPIJob("foo", newInt32(1), nil, nil)
+	job.Annotations = map[string]string{sshPortAnnotation: "20005"}
+	job.Spec.MPIImplementation = kubeflow.MPIImplementationCharm
+	job.Spec.SSHOptions = &kubeflow.SSHOptions{ServerAliveInterval: newInt32(30)}
+	scheme.Scheme.Default(job)
+	ctrl := &MPIJobController{}
+
+	launcher := ctrl.newLauncherJob(job)
+	want := corev1.EnvVar{Name: charmRemoteShellEnv, Value: "ssh -o ConnectionAttempts=10 -o ServerAliveInterval=30 -p 20005"}
+	if !hasEnvVar(launcher.Spec.Template.Spec.Containers[0].Env, want) {
+		t.Errorf("Launcher is missing environment variable %+v", want)
+	}
+}
+
+func TestConfigMapWithHostDiscoveryNetwork(t *testing.T) {
+	job := newMPIJob("foo", newInt32(2), nil, nil)
+	job.Spec.HostDiscoveryNetwork = "sriov"
+	scheme.Scheme.Default(job)
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-worker-0",
+				Namespace: metav1.NamespaceDefault,
+				Annotations: map[string]string{
+					networkStatusAnnotation: `[{"name":"kindnet","ips":["10.0.0.5"],"default":true},{"name":"default/sriov","ips":["192.168.1.5"]}]`,
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-worker-1",
+				Namespace: metav1.NamespaceDefault,
+			},
+		},
+	}
+
+	cm := newConfigMap(job, 2, workerAddresses(job, pods), nil)
+	updateDiscoverHostsInConfigMap(cm, job, pods, nil)
+	want := map[string]string{
+		hostfileName:            "192.168.1.5 slots=1\nfoo-worker-1.foo-worker slots=1\n",
+		discoverHostsScriptName: "#!/bin/sh\necho 192.168.1.5\necho foo-worker-1.foo-worker.default.svc\n",
+	}
+	if diff := cmp.Diff(want, cm.Data); diff != "" {
+		t.Errorf("Unexpected ConfigMap data (-want,+got):\n%s", diff)
+	}
+}
+
+func TestConfigMapHostfileFormat(t *testing.T) {
+	cases := map[kubeflow.MPIImplementation]string{
+		kubeflow.MPIImplementationOpenMPI: "foo-worker-0.foo-worker slots=2\nfoo-worker-1.foo-worker slots=2\n",
+		kubeflow.MPIImplementationIntel:   "foo-worker-0.foo-worker:2\nfoo-worker-1.foo-worker:2\n",
+		kubeflow.MPIImplementationCharm:   "host foo-worker-0.foo-worker ++cpus 2\nhost foo-worker-1.foo-worker ++cpus 2\n",
+	}
+	for impl, want := range cases {
+		t.Run(string(impl), func(t *testing.T) {
+			job := newMPIJob("foo", newInt32(2), nil, nil)
+			job.Spec.SlotsPerWorker = newInt32(2)
+			job.Spec.MPIImplementation = impl
+			cm := newConfigMap(job, 2, nil, nil)
+			if diff := cmp.Diff(want, cm.Data[hostfileName]); diff != "" {
+				t.Errorf("Unexpected hostfile (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigMapSortedByTopology(t *testing.T) {
+	job := newMPIJob("foo", newInt32(4), nil, nil)
+	scheme.Scheme.Default(job)
+	nodes := map[string]string{
+		"node-a": "rack-2",
+		"node-b": "rack-1",
+		"node-c": "rack-2",
+	}
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo-worker-3", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "node-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo-worker-0", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "node-c"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "foo-worker-1", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "node-a"}},
+	}
+	k8sI := kubeinformers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), noResyncPeriodFunc())
+	for name, rack := range nodes {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"rack": rack}}}
+		if err := k8sI.Core().V1().Nodes().Informer().GetIndexer().Add(node); err != nil {
+			t.Fatalf("Failed adding Node to informer: %v", err)
+		}
+	}
+	c := &MPIJobController{
+		nodeLister:   k8sI.Core().V1().Nodes().Lister(),
+		topologyKeys: []string{"rack"},
+	}
+
+	topology := c.workerTopology(pods)
+	cm := newConfigMap(job, 4, nil, topology)
+	updateDiscoverHostsInConfigMap(cm, job, pods, topology)
+	want := map[string]string{
+		hostfileName:            "foo-worker-3.foo-worker slots=1\nfoo-worker-1.foo-worker slots=1\nfoo-worker-0.foo-worker slots=1\nfoo-worker-2.foo-worker slots=1\n",
+		discoverHostsScriptName: "#!/bin/sh\necho foo-worker-3.foo-worker.default.svc\necho foo-worker-1.foo-worker.default.svc\necho foo-worker-0.foo-worker.default.svc\n",
+	}
+	if diff := cmp.Diff(want, cm.Data); diff != "" {
+		t.Errorf("Unexpected ConfigMap data (-want,+got):\n%s", diff)
+	}
+}
+
+func TestSetupProfiling(t *testing.T) {
+	job := newMPIJob("foo", newInt32(1), nil, nil)
+	job.Spec.Profiling = &kubeflow.Profiling{
+		Env: map[string]string{
+			"MPIP":       "-f /profile",
+			"LD_PRELOAD": "/usr/lib/libmpiP.so",
+		},
+		OutputClaimName: "results",
+	}
+	scheme.Scheme.Default(job)
+	wantVolume := corev1.Volume{
+		Name: profileOutputVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "results"},
+		},
+	}
+	wantMount := corev1.VolumeMount{Name: profileOutputVolume, MountPath: "/profile"}
+	wantEnv := []corev1.EnvVar{
+		{Name: "LD_PRELOAD", Value: "/usr/lib/libmpiP.so"},
+		{Name: "MPIP", Value: "-f /profile"},
+		{Name: profileDirEnv, Value: "/profile"},
+	}
+
+	cases := map[string]struct {
+		isLauncher bool
+		env        []corev1.EnvVar
+		wantEnv    []corev1.EnvVar
+	}{
+		"worker": {
+			wantEnv: wantEnv,
+		},
+		"launcher": {
+			isLauncher: true,
+			wantEnv:    append(wantEnv, corev1.EnvVar{Name: openMPIEnvListEnv, Value: "LD_PRELOAD;MPIP;K_MPI_PROFILE_DIR"}),
+		},
+		"launcher with forwarded variables": {
+			isLauncher: true,
+			env:        []corev1.EnvVar{{Name: openMPIEnvListEnv, Value: "FOO"}},
+			wantEnv:    append([]corev1.EnvVar{{Name: openMPIEnvListEnv, Value: "FOO;LD_PRELOAD;MPIP;K_MPI_PROFILE_DIR"}}, wantEnv...),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			podSpec := corev1.PodSpec{
+				Containers: []corev1.Container{{Env: tc.env}},
+			}
+			setupProfiling(&podSpec, job, tc.isLauncher)
+			if diff := cmp.Diff(tc.wantEnv, podSpec.Containers[0].Env); diff != "" {
+				t.Errorf("Unexpected env (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]corev1.Volume{wantVolume}, podSpec.Volumes); diff != "" {
+				t.Errorf("Unexpected volumes (-want,+got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]corev1.VolumeMount{wantMount}, podSpec.Containers[0].VolumeMounts); diff != "" {
+				t.Errorf("Unexpected volume mounts (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetSlotsFromResource(t *testing.T) {
+	const gpu corev1.ResourceName = "nvidia.com/gpu"
+	cases := map[string]struct {
+		slots      *int32
+		containers []corev1.Container
+		wantSlots  *int32
+	}{
+		"slots set": {
+			slots: newInt32(2),
+			containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{gpu: resource.MustParse("4")},
+				},
+			}},
+			wantSlots: newInt32(2),
+		},
+		"limits": {
+			containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{gpu: resource.MustParse("4")},
+				},
+			}},
+			wantSlots: newInt32(4),
+		},
+		"requests in several containers": {
+			containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{gpu: resource.MustParse("2")},
+						Limits:   corev1.ResourceList{gpu: resource.MustParse("2")},
+					},
+				},
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{gpu: resource.MustParse("1")},
+					},
+				},
+			},
+			wantSlots: newInt32(3),
+		},
+		"resource not requested": {
+			containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+				},
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			job := newMPIJob("foo", newInt32(1), nil, nil)
+			job.Spec.SlotsPerWorker = tc.slots
+			job.Spec.MPIReplicaSpecs[kubeflow.MPIReplicaTypeWorker].Template.Spec.Containers = tc.containers
+			setSlotsFromResource(job, gpu)
+			if diff := cmp.Diff(tc.wantSlots, job.Spec.SlotsPerWorker); diff != "" {
+				t.Errorf("Unexpected slots per worker (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMetadataPolicy(t *testing.T) {
+	job := newMPIJob("foo", newInt32(1), nil, nil)
+	job.Spec.MetadataPolicy = &kubeflow.MetadataPolicy{
+		Labels: map[string]string{
+			"team":              "ml",
+			common.JobRoleLabel: "other",
+		},
+		Annotations: map[string]string{"cost-center": "42"},
+	}
+	scheme.Scheme.Default(job)
+	c := &MPIJobController{}
+	secret, err := newSSHAuthSecret(job)
+	if err != nil {
+		t.Fatalf("Creating SSH auth secret: %v", err)
+	}
+	launcher := c.newLauncherJob(job)
+	workerPod := c.newWorker(job, 0)
+	objects := map[string]metav1.Object{
+		"workers service": newWorkersService(job),
+		"configmap":       newConfigMap(job, 1, nil, nil),
+		"secret":          secret,
+		"launcher job":    launcher,
+		"launcher pod":    &launcher.Spec.Template,
+		"worker":          workerPod,
+	}
+	for name, obj := range objects {
+		if got := obj.GetLabels()["team"]; got != "ml" {
+			t.Errorf("%s has label team=%q, want ml", name, got)
+		}
+		if got := obj.GetAnnotations()["cost-center"]; got != "42" {
+			t.Errorf("%s has annotation cost-center=%q, want 42", name, got)
+		}
+	}
+	if got := workerPod.Labels[common.JobRoleLabel]; got != worker {
+		t.Errorf("Worker has label %s=%q, want %s", common.JobRoleLabel, got, worker)
+	}
+}
+
+func hasEnvVar(envs []corev1.EnvVar, want corev1.EnvVar) bool {
+	for _, ev := range envs {
+		if ev == want {
+			return true
+		}
+	}
+	return false
+}
+
 func TestNewLauncherAndWorker(t *testing.T) {
 	cases := map[string]struct {
 		job          kubeflow.MPIJob
@@ -986,7 +2559,8 @@ func TestNewLauncherAndWorker(t *testing.T) {
 					Name:      "foo-launcher",
 					Namespace: "bar",
 					Labels: map[string]string{
-						"app": "foo",
+						"app":                    "foo",
+						common.OperatorNameLabel: kubeflow.OperatorName,
 					},
 				},
 				Spec: batchv1.JobSpec{
@@ -1007,6 +2581,7 @@ func TestNewLauncherAndWorker(t *testing.T) {
 									Env: joinEnvVars(
 										launcherEnvVars,
 										ompiEnvVars,
+										corev1.EnvVar{Name: openMPIRshArgsEnv, Value: "-o ConnectionAttempts=10"},
 										corev1.EnvVar{Name: openMPISlotsEnv, Value: "1"},
 										nvidiaDisableEnvVars),
 									VolumeMounts: []corev1.VolumeMount{
@@ -1149,7 +2724,8 @@ func TestNewLauncherAndWorker(t *testing.T) {
 					Name:      "bar-launcher",
 					Namespace: "foo",
 					Labels: map[string]string{
-						"app": "bar",
+						"app":                    "bar",
+						common.OperatorNameLabel: kubeflow.OperatorName,
 					},
 				},
 				Spec: batchv1.JobSpec{
@@ -1180,6 +2756,8 @@ func TestNewLauncherAndWorker(t *testing.T) {
 										corev1.EnvVar{Name: "FOO", Value: "bar"},
 										launcherEnvVars,
 										intelEnvVars,
+										corev1.EnvVar{Name: intelBootstrapArgsEnv, Value: "-o ConnectionAttempts=10"},
+										corev1.EnvVar{Name: hydraLaunchArgsEnv, Value: "-o ConnectionAttempts=10"},
 										corev1.EnvVar{Name: "I_MPI_PERHOST", Value: "5"},
 										nvidiaDisableEnvVars),
 									VolumeMounts: []corev1.VolumeMount{
@@ -0,0 +1,59 @@
+// Copyright 2021 The Kubeflow Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// workerTopology returns the location of the running workers, keyed by Pod
+// name. A location has the values of the topology keys in the labels of the
+// worker's node, followed by the node name.
+func (c *MPIJobController) workerTopology(pods []*corev1.Pod) map[string][]string {
+	topology := make(map[string][]string, len(pods))
+	for _, p := range pods {
+		if p.Spec.NodeName == "" {
+			continue
+		}
+		var nodeLabels map[string]string
+		if node, err := c.nodeLister.Get(p.Spec.NodeName); err == nil {
+			nodeLabels = node.Labels
+		}
+		location := make([]string, 0, len(c.topologyKeys)+1)
+		for _, k := range c.topologyKeys {
+			location = append(location, nodeLabels[k])
+		}
+		topology[p.Name] = append(location, p.Spec.NodeName)
+	}
+	return topology
+}
+
+// lessByTopology returns whether worker a is listed before worker b in the
+// hostfile and discover_hosts.sh, so that workers in the same location are
+// listed next to each other, and so are the locations that share their outer
+// levels. Workers without a known location are listed last.
+func lessByTopology(topology map[string][]string, a, b string) bool {
+	aLoc, aOK := topology[a]
+	bLoc, bOK := topology[b]
+	if !aOK || !bOK {
+		return aOK && !bOK
+	}
+	for k := 0; k < len(aLoc) && k < len(bLoc); k++ {
+		if aLoc[k] != bLoc[k] {
+			return aLoc[k] < bLoc[k]
+		}
+	}
+	return false
+}